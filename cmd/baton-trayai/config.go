@@ -1,21 +1,107 @@
 package main
 
 import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
 	"github.com/conductorone/baton-sdk/pkg/field"
+	"github.com/conductorone/baton-trayai/pkg/connector"
+	trayclient "github.com/conductorone/baton-trayai/pkg/connector/client"
 	"github.com/spf13/viper"
 )
 
+const (
+	defaultRegion = "us"
+	// customRegion lets the connector target an API that isn't one of the known tray.ai regions.
+	customRegion = "custom"
+)
+
 var (
 	AuthorizationTokenField = field.StringField(
 		"auth-token",
 		field.WithDescription("auth-token for authenticating with the service"),
 		field.WithRequired(true),
 	)
+	RegionField = field.StringField(
+		"region",
+		field.WithDescription("The tray.ai region of the organization: us, eu, apac, or custom to use base-url"),
+		field.WithDefaultValue(defaultRegion),
+	)
+	BaseURLField = field.StringField(
+		"base-url",
+		field.WithDescription("The base URL of the tray.ai API, only used when region is custom"),
+	)
+	GraphQLURLField = field.StringField(
+		"graphql-url",
+		field.WithDescription("The URL of the tray.ai Embedded GraphQL API. Defaults to the GraphQL API of the region, or /graphql on base-url when region is custom"),
+	)
+	ExcludeExternalUsersField = field.BoolField(
+		"exclude-external-users",
+		field.WithDescription("Skip the Embedded end-customer (external) users when syncing users"),
+		field.WithDefaultValue(false),
+	)
+	SuccessorUserIDField = field.StringField(
+		"successor-user-id",
+		field.WithDescription("ID of the tray.ai user who takes over the workspaces owned by a deleted user. Solutions belong to the organization and aren't transferred"),
+	)
+	SyncUserUsageField = field.BoolField(
+		"sync-user-usage",
+		field.WithDescription("Sync the last login and task usage of users. Costs one extra API call per user"),
+		field.WithDefaultValue(false),
+	)
+	DeleteOrphanedAuthenticationsOnlyField = field.BoolField(
+		"delete-orphaned-authentications-only",
+		field.WithDescription("Only allow deleting authentications whose owner is disabled or no longer exists"),
+		field.WithDefaultValue(true),
+	)
+	SyncUsersField = field.StringSliceField(
+		"sync-users",
+		field.WithDescription("Only sync these users, given by email or ID, to refresh them without a full sync. Requires resource-types to be user"),
+	)
+	PageSizeField = field.IntField(
+		"page-size",
+		field.WithDescription(fmt.Sprintf("Number of items fetched per page from the tray.ai API, at most %d", trayclient.MaxPageSize)),
+		field.WithDefaultValue(trayclient.DefaultPageSize),
+	)
+	ResourceTypesField = field.StringSliceField(
+		"resource-types",
+		field.WithDescription("Only sync these resource types, e.g. user,organization. All of them are synced when empty"),
+	)
+	SkipResourceTypesField = field.StringSliceField(
+		"skip-resource-types",
+		field.WithDescription("Resource types not to sync, e.g. authentication,api_token"),
+	)
+	IncludeWorkspacesField = field.StringSliceField(
+		"include-workspaces",
+		field.WithDescription("Only sync the workspaces matching one of these IDs or name globs"),
+	)
+	ExcludeWorkspacesField = field.StringSliceField(
+		"exclude-workspaces",
+		field.WithDescription("Skip the workspaces matching one of these IDs or name globs, along with their projects, workflows and authentications"),
+	)
 
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
-	ConfigurationFields = []field.SchemaField{AuthorizationTokenField}
+	ConfigurationFields = []field.SchemaField{
+		AuthorizationTokenField,
+		RegionField,
+		BaseURLField,
+		GraphQLURLField,
+		ExcludeExternalUsersField,
+		SuccessorUserIDField,
+		SyncUserUsageField,
+		DeleteOrphanedAuthenticationsOnlyField,
+		SyncUsersField,
+		PageSizeField,
+		ResourceTypesField,
+		SkipResourceTypesField,
+		IncludeWorkspacesField,
+		ExcludeWorkspacesField,
+	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
@@ -29,5 +115,84 @@ var (
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	if err := filters(v).Validate(); err != nil {
+		return err
+	}
+	if err := filters(v).ValidateSyncUsers(stringSlice(v, SyncUsersField.FieldName)); err != nil {
+		return err
+	}
+
+	// A page-size of 0 leaves the default page size.
+	if pageSize := v.GetInt(PageSizeField.FieldName); pageSize < 0 || pageSize > trayclient.MaxPageSize {
+		return fmt.Errorf("page-size must be between 1 and %d, or 0 for the default of %d, got %d",
+			trayclient.MaxPageSize, trayclient.DefaultPageSize, pageSize)
+	}
+
+	if rawGraphQLURL := v.GetString(GraphQLURLField.FieldName); rawGraphQLURL != "" && !isHTTPURL(rawGraphQLURL) {
+		return fmt.Errorf("graphql-url must be an absolute http(s) URL, got %q", rawGraphQLURL)
+	}
+
+	region := v.GetString(RegionField.FieldName)
+	rawBaseURL := v.GetString(BaseURLField.FieldName)
+
+	if region == customRegion {
+		if rawBaseURL == "" {
+			return fmt.Errorf("base-url is required when region is %s", customRegion)
+		}
+		if !isHTTPURL(rawBaseURL) {
+			return fmt.Errorf("base-url must be an absolute http(s) URL, got %q", rawBaseURL)
+		}
+		return nil
+	}
+
+	if rawBaseURL != "" {
+		return fmt.Errorf("base-url can only be set when region is %s", customRegion)
+	}
+	if _, ok := trayclient.RegionBaseURLs[region]; region != "" && !ok {
+		regions := append(slices.Sorted(maps.Keys(trayclient.RegionBaseURLs)), customRegion)
+		return fmt.Errorf("unknown region %q, expected one of: %s", region, strings.Join(regions, ", "))
+	}
 	return nil
 }
+
+// isHTTPURL reports whether raw is an absolute http(s) URL.
+func isHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// filters returns the resource type and workspace filters of the sync.
+func filters(v *viper.Viper) connector.Filters {
+	return connector.Filters{
+		ResourceTypes:     stringSlice(v, ResourceTypesField.FieldName),
+		SkipResourceTypes: stringSlice(v, SkipResourceTypesField.FieldName),
+		IncludeWorkspaces: stringSlice(v, IncludeWorkspacesField.FieldName),
+		ExcludeWorkspaces: stringSlice(v, ExcludeWorkspacesField.FieldName),
+	}
+}
+
+// stringSlice returns the values of a list field. Lists set through environment variables reach viper as a
+// single comma-separated string, so the values are split on commas as well.
+func stringSlice(v *viper.Viper, name string) []string {
+	var values []string
+	for _, raw := range v.GetStringSlice(name) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// baseURL returns the base URL of the tray.ai API for the configured region.
+func baseURL(v *viper.Viper) string {
+	region := v.GetString(RegionField.FieldName)
+	switch region {
+	case customRegion:
+		return v.GetString(BaseURLField.FieldName)
+	case "":
+		region = defaultRegion
+	}
+	return trayclient.RegionBaseURLs[region]
+}
@@ -25,6 +25,147 @@ func TestConfigs(t *testing.T) {
 				"auth-token": "",
 			},
 		},
+		{
+			Configs: map[string]string{
+				"auth-token":             "abc123",
+				"exclude-external-users": "true",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":      "abc123",
+				"sync-user-usage": "true",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":                           "abc123",
+				"delete-orphaned-authentications-only": "false",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "eu",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "mars",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":  "abc123",
+				"graphql-url": "https://tray.io/graphql",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":  "abc123",
+				"graphql-url": "tray.io/graphql",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "custom",
+				"base-url":   "http://127.0.0.1:8080",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "custom",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "custom",
+				"base-url":   "api.eu1.tray.io",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"region":     "us",
+				"base-url":   "https://api.eu1.tray.io",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":     "abc123",
+				"sync-users":     "ada@example.com,u2",
+				"resource-types": "user",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"sync-users": "ada@example.com,u2",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":     "abc123",
+				"sync-users":     "u2",
+				"resource-types": "user,workspace",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"page-size":  "100",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"page-size":  "-1",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token": "abc123",
+				"page-size":  "500",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":         "abc123",
+				"resource-types":     "user,organization",
+				"exclude-workspaces": "sandbox-*,ws-123",
+			},
+			IsValid: true,
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":     "abc123",
+				"resource-types": "user,flows",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":          "abc123",
+				"skip-resource-types": "workspace",
+			},
+		},
+		{
+			Configs: map[string]string{
+				"auth-token":         "abc123",
+				"include-workspaces": "[prod",
+			},
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
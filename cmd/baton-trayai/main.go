@@ -48,8 +48,18 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
-	authToken := v.GetString(AuthorizationTokenField.FieldName)
-	cb, err := connector.New(ctx, authToken)
+	cb, err := connector.New(ctx, connector.Config{
+		AuthToken:                         v.GetString(AuthorizationTokenField.FieldName),
+		BaseURL:                           baseURL(v),
+		GraphQLURL:                        v.GetString(GraphQLURLField.FieldName),
+		ExcludeExternalUsers:              v.GetBool(ExcludeExternalUsersField.FieldName),
+		SuccessorUserID:                   v.GetString(SuccessorUserIDField.FieldName),
+		SyncUserUsage:                     v.GetBool(SyncUserUsageField.FieldName),
+		DeleteOrphanedAuthenticationsOnly: v.GetBool(DeleteOrphanedAuthenticationsOnlyField.FieldName),
+		SyncUsers:                         stringSlice(v, SyncUsersField.FieldName),
+		PageSize:                          v.GetInt(PageSizeField.FieldName),
+		Filters:                           filters(v),
+	})
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
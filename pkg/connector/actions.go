@@ -0,0 +1,270 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	config "github.com/conductorone/baton-sdk/pb/c1/config/v1"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	trayclient "github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The custom actions exposed by the connector.
+const (
+	disableWorkflowAction         = "disable_workflow"
+	enableWorkflowAction          = "enable_workflow"
+	disableSolutionInstanceAction = "disable_solution_instance"
+	triggerCallableWorkflowAction = "trigger_callable_workflow"
+)
+
+// The arguments and return values of the custom actions.
+const (
+	workflowIDField         = "workflow_id"
+	solutionInstanceIDField = "solution_instance_id"
+	inputField              = "input"
+	executionIDField        = "execution_id"
+	outputField             = "output"
+	errorField              = "error"
+	successField            = "success"
+)
+
+func stringField(name, displayName, description string, required bool) *config.Field {
+	return &config.Field{
+		Name:        name,
+		DisplayName: displayName,
+		Description: description,
+		IsRequired:  required,
+		Field:       &config.Field_StringField{StringField: &config.StringField{}},
+	}
+}
+
+var (
+	successReturnField = &config.Field{
+		Name:        successField,
+		DisplayName: "Success",
+		Field:       &config.Field_BoolField{BoolField: &config.BoolField{}},
+	}
+
+	actionSchemas = []*v2.BatonActionSchema{
+		{
+			Name:        disableWorkflowAction,
+			DisplayName: "Disable workflow",
+			Description: "Disable a tray.ai workflow so that its trigger no longer starts runs",
+			Arguments: []*config.Field{
+				stringField(workflowIDField, "Workflow ID", "The ID of the workflow to disable", true),
+			},
+			ReturnTypes: []*config.Field{successReturnField},
+		},
+		{
+			Name:        enableWorkflowAction,
+			DisplayName: "Enable workflow",
+			Description: "Enable a disabled tray.ai workflow",
+			Arguments: []*config.Field{
+				stringField(workflowIDField, "Workflow ID", "The ID of the workflow to enable", true),
+			},
+			ReturnTypes: []*config.Field{successReturnField},
+		},
+		{
+			Name:        disableSolutionInstanceAction,
+			DisplayName: "Disable solution instance",
+			Description: "Disable a tray.ai Embedded solution instance",
+			Arguments: []*config.Field{
+				stringField(solutionInstanceIDField, "Solution instance ID", "The ID of the solution instance to disable", true),
+			},
+			ReturnTypes: []*config.Field{successReturnField},
+		},
+		{
+			Name:        triggerCallableWorkflowAction,
+			DisplayName: "Trigger callable workflow",
+			Description: "Start a run of a tray.ai callable workflow; the run is tracked until it finishes",
+			Arguments: []*config.Field{
+				stringField(workflowIDField, "Workflow ID", "The ID of the callable workflow to trigger", true),
+				stringField(inputField, "Input", "The input passed to the workflow trigger, as a JSON object", false),
+			},
+			ReturnTypes: []*config.Field{
+				stringField(executionIDField, "Execution ID", "The ID of the workflow run", false),
+				stringField(outputField, "Output", "The output of the workflow run once it succeeded, as a JSON object", false),
+				stringField(errorField, "Error", "The error of the workflow run once it failed", false),
+			},
+		},
+	}
+)
+
+// RegisterActionManager returns the manager of the tray.ai custom actions.
+func (d *Connector) RegisterActionManager(_ context.Context) (connectorbuilder.CustomActionManager, error) {
+	return &actionManager{client: d.client}, nil
+}
+
+// actionManager runs the custom actions against tray.ai. Enabling and disabling complete synchronously, while
+// triggering a callable workflow returns the ID of the workflow run whose progress is polled by GetActionStatus.
+// Action IDs are the action name and, after a colon, the workflow run ID or a random ID for the synchronous
+// actions, see actionID.
+type actionManager struct {
+	client *trayclient.Client
+}
+
+func (m *actionManager) ListActionSchemas(_ context.Context) ([]*v2.BatonActionSchema, annotations.Annotations, error) {
+	return actionSchemas, nil, nil
+}
+
+func (m *actionManager) GetActionSchema(_ context.Context, name string) (*v2.BatonActionSchema, annotations.Annotations, error) {
+	for _, schema := range actionSchemas {
+		if schema.GetName() == name {
+			return schema, nil, nil
+		}
+	}
+	return nil, nil, status.Errorf(codes.NotFound, "baton-trayai: unknown action %q", name)
+}
+
+func (m *actionManager) InvokeAction(
+	ctx context.Context,
+	name string,
+	args *structpb.Struct,
+) (string, v2.BatonActionStatus, *structpb.Struct, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	switch name {
+	case disableWorkflowAction, enableWorkflowAction:
+		workflowID, err := requiredStringArg(args, workflowIDField)
+		if err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+		}
+		enabled := name == enableWorkflowAction
+		if err := m.client.SetWorkflowEnabled(ctx, workflowID, enabled); err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, fmt.Errorf("baton-trayai: SetWorkflowEnabled failed: %w", err)
+		}
+		l.Info("baton-trayai: workflow updated", zap.String("workflow_id", workflowID), zap.Bool("enabled", enabled))
+		return actionID(name, uuid.NewString()), v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, successResponse(), nil, nil
+
+	case disableSolutionInstanceAction:
+		instanceID, err := requiredStringArg(args, solutionInstanceIDField)
+		if err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+		}
+		if _, err := m.client.SetSolutionInstanceEnabled(ctx, instanceID, false); err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, fmt.Errorf("baton-trayai: SetSolutionInstanceEnabled failed: %w", err)
+		}
+		l.Info("baton-trayai: solution instance disabled", zap.String("solution_instance_id", instanceID))
+		return actionID(name, uuid.NewString()), v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, successResponse(), nil, nil
+
+	case triggerCallableWorkflowAction:
+		workflowID, err := requiredStringArg(args, workflowIDField)
+		if err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+		}
+		var input map[string]interface{}
+		if raw := args.GetFields()[inputField].GetStringValue(); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &input); err != nil {
+				return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil,
+					status.Errorf(codes.InvalidArgument, "baton-trayai: argument %s must be a JSON object: %v", inputField, err)
+			}
+		}
+		execution, err := m.client.TriggerCallableWorkflow(ctx, workflowID, input)
+		if err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, fmt.Errorf("baton-trayai: TriggerCallableWorkflow failed: %w", err)
+		}
+		resp, err := executionResponse(execution)
+		if err != nil {
+			return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, err
+		}
+		return actionID(name, execution.ID), executionStatus(execution.Status), resp, nil, nil
+
+	default:
+		return "", v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, nil, nil, status.Errorf(codes.NotFound, "baton-trayai: unknown action %q", name)
+	}
+}
+
+// GetActionStatus reports the progress of a workflow run started by the trigger_callable_workflow action. The
+// synchronous actions only get an ID once complete, so they are reported complete without calling tray.ai.
+func (m *actionManager) GetActionStatus(
+	ctx context.Context,
+	id string,
+) (v2.BatonActionStatus, string, *structpb.Struct, annotations.Annotations, error) {
+	name, executionID, _ := strings.Cut(id, ":")
+	switch {
+	case name == disableWorkflowAction, name == enableWorkflowAction, name == disableSolutionInstanceAction:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, name, successResponse(), nil, nil
+	case name != triggerCallableWorkflowAction || executionID == "":
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN, "", nil, nil, status.Errorf(codes.NotFound, "baton-trayai: unknown action ID %q", id)
+	}
+
+	// A cached response would report the run stuck in the state of the first poll.
+	execution, err := m.client.GetWorkflowExecution(ctx, executionID, trayclient.WithoutCache())
+	if err != nil {
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN, triggerCallableWorkflowAction, nil, nil,
+			fmt.Errorf("baton-trayai: GetWorkflowExecution failed: %w", err)
+	}
+	resp, err := executionResponse(execution)
+	if err != nil {
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN, triggerCallableWorkflowAction, nil, nil, err
+	}
+	return executionStatus(execution.Status), triggerCallableWorkflowAction, resp, nil, nil
+}
+
+// actionID returns the ID of a run of the named action, ref being the workflow run it started or a random ID.
+func actionID(name, ref string) string {
+	return name + ":" + ref
+}
+
+// executionStatus maps the status of a tray.ai workflow run to the status of the action that started it.
+func executionStatus(s string) v2.BatonActionStatus {
+	switch s {
+	case trayclient.WorkflowExecutionPending:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_PENDING
+	case trayclient.WorkflowExecutionRunning:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_RUNNING
+	case trayclient.WorkflowExecutionSucceeded:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE
+	case trayclient.WorkflowExecutionFailed, trayclient.WorkflowExecutionCancelled:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED
+	default:
+		return v2.BatonActionStatus_BATON_ACTION_STATUS_UNKNOWN
+	}
+}
+
+func executionResponse(execution *trayclient.WorkflowExecution) (*structpb.Struct, error) {
+	fields := map[string]interface{}{
+		executionIDField: execution.ID,
+	}
+	if execution.Output != nil {
+		output, err := json.Marshal(execution.Output)
+		if err != nil {
+			return nil, fmt.Errorf("baton-trayai: failed to encode the output of workflow run %s: %w", execution.ID, err)
+		}
+		fields[outputField] = string(output)
+	}
+	if execution.Error != "" {
+		fields[errorField] = execution.Error
+	}
+	resp, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, fmt.Errorf("baton-trayai: failed to build the response of workflow run %s: %w", execution.ID, err)
+	}
+	return resp, nil
+}
+
+func successResponse() *structpb.Struct {
+	return &structpb.Struct{
+		Fields: map[string]*structpb.Value{
+			successField: structpb.NewBoolValue(true),
+		},
+	}
+}
+
+func requiredStringArg(args *structpb.Struct, name string) (string, error) {
+	value := args.GetFields()[name].GetStringValue()
+	if value == "" {
+		return "", status.Errorf(codes.InvalidArgument, "baton-trayai: missing required argument %s", name)
+	}
+	return value, nil
+}
@@ -0,0 +1,121 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestActions(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPatch && r.URL.Path == "/core/v1/workflows/wf1":
+			var body map[string]bool
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.False(t, body["enabled"])
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/core/v1/workflows/wf1/trigger":
+			var body struct {
+				Input map[string]interface{} `json:"input"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "jane@example.com", body.Input["email"])
+			_, _ = w.Write([]byte(`{"id": "ex1", "workflowId": "wf1", "status": "running"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/core/v1/workflow-executions/ex1":
+			_, _ = w.Write([]byte(`{"id": "ex1", "workflowId": "wf1", "status": "succeeded", "output": {"ticket": "T-1"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	m, err := c.RegisterActionManager(ctx)
+	require.NoError(t, err)
+
+	schemas, _, err := m.ListActionSchemas(ctx)
+	require.NoError(t, err)
+	require.Len(t, schemas, 4)
+
+	_, actionStatus, _, _, err := m.InvokeAction(ctx, disableWorkflowAction, &structpb.Struct{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_FAILED, actionStatus)
+
+	args, err := structpb.NewStruct(map[string]interface{}{workflowIDField: "wf1"})
+	require.NoError(t, err)
+	disableID, actionStatus, _, _, err := m.InvokeAction(ctx, disableWorkflowAction, args)
+	require.NoError(t, err)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, actionStatus)
+	require.NotContains(t, disableID, "wf1")
+
+	// Every run of a synchronous action gets its own ID, reported complete without polling tray.ai.
+	otherDisableID, _, _, _, err := m.InvokeAction(ctx, disableWorkflowAction, args)
+	require.NoError(t, err)
+	require.NotEqual(t, disableID, otherDisableID)
+	actionStatus, name, _, _, err := m.GetActionStatus(ctx, disableID)
+	require.NoError(t, err)
+	require.Equal(t, disableWorkflowAction, name)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, actionStatus)
+
+	args, err = structpb.NewStruct(map[string]interface{}{
+		workflowIDField: "wf1",
+		inputField:      `{"email": "jane@example.com"}`,
+	})
+	require.NoError(t, err)
+	id, actionStatus, _, _, err := m.InvokeAction(ctx, triggerCallableWorkflowAction, args)
+	require.NoError(t, err)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_RUNNING, actionStatus)
+
+	actionStatus, name, resp, _, err := m.GetActionStatus(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, triggerCallableWorkflowAction, name)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, actionStatus)
+	require.Equal(t, "ex1", resp.GetFields()[executionIDField].GetStringValue())
+	require.JSONEq(t, `{"ticket": "T-1"}`, resp.GetFields()[outputField].GetStringValue())
+
+	args, err = structpb.NewStruct(map[string]interface{}{workflowIDField: "wf1", inputField: "email=jane@example.com"})
+	require.NoError(t, err)
+	_, _, _, _, err = m.InvokeAction(ctx, triggerCallableWorkflowAction, args)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	for _, unknown := range []string{"ex1", "wf1", "rotate:x", triggerCallableWorkflowAction + ":"} {
+		_, _, _, _, err = m.GetActionStatus(ctx, unknown)
+		require.Equal(t, codes.NotFound, status.Code(err), unknown)
+	}
+}
+
+func TestGetActionStatusBypassesCache(t *testing.T) {
+	polls := 0
+	c := newCachingTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/workflow-executions/ex1", r.URL.Path)
+		polls++
+		state := "running"
+		if polls > 1 {
+			state = "succeeded"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "ex1", "workflowId": "wf1", "status": "` + state + `"}`))
+	})
+
+	ctx := context.Background()
+	m, err := c.RegisterActionManager(ctx)
+	require.NoError(t, err)
+
+	id := actionID(triggerCallableWorkflowAction, "ex1")
+	actionStatus, _, _, _, err := m.GetActionStatus(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_RUNNING, actionStatus)
+
+	actionStatus, _, _, _, err = m.GetActionStatus(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, v2.BatonActionStatus_BATON_ACTION_STATUS_COMPLETE, actionStatus)
+	require.Equal(t, 2, polls)
+}
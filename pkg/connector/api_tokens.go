@@ -0,0 +1,97 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+)
+
+// Create a new connector resource for an API token of a tray.ai service account.
+func apiTokenResource(
+	_ context.Context,
+	token client.APIToken,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	traitOptions := []resource.SecretTraitOption{
+		resource.WithSecretIdentityID(parentResourceID),
+	}
+	if !token.CreatedAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithSecretCreatedAt(token.CreatedAt))
+	}
+	if !token.LastUsedAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithSecretLastUsedAt(token.LastUsedAt))
+	}
+	if !token.ExpiresAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithSecretExpiresAt(token.ExpiresAt))
+	}
+
+	return resource.NewSecretResource(
+		token.Name,
+		apiTokenResourceType,
+		token.ID,
+		traitOptions,
+		resource.WithParentResourceID(parentResourceID),
+	)
+}
+
+type apiTokenBuilder struct {
+	client *client.Client
+}
+
+func (o *apiTokenBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return apiTokenResourceType
+}
+
+// List returns the API tokens of the parent service account as resource objects.
+func (o *apiTokenBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != serviceAccountResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	var (
+		tokens []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListServiceAccountTokens(ctx, client.ListServiceAccountTokensParams{
+		ServiceAccountID: parentResourceID.Resource,
+		Cursor:           pToken.Token,
+		First:            pToken.Size,
+	})
+	if unavailable(ctx, err, apiTokenResourceType) {
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListServiceAccountTokens failed: %w", err)
+	}
+
+	for _, token := range resp.Tokens {
+		vToken, err := apiTokenResource(ctx, token, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		tokens = append(tokens, vToken)
+	}
+
+	return tokens, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements always returns an empty slice for API tokens.
+func (o *apiTokenBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for API tokens since they don't have any entitlements.
+func (o *apiTokenBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newAPITokenBuilder(c *client.Client) *apiTokenBuilder {
+	return &apiTokenBuilder{
+		client: c,
+	}
+}
@@ -0,0 +1,58 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAPITokens(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/service-accounts/sa1/tokens", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "t1", "name": "deploy", "createdAt": "2024-05-01T10:00:00Z", "lastUsedAt": "2024-06-01T10:00:00Z",
+				 "expiresAt": "2025-05-01T10:00:00Z"},
+				{"id": "t2", "name": "unused"}
+			],
+			"pageInfo": {}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newAPITokenBuilder(c.client)
+
+	// Tokens are only listed under their service account.
+	tokens, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	serviceAccountID := &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa1"}
+	tokens, next, _, err := builder.List(ctx, serviceAccountID, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	require.Empty(t, next)
+
+	require.Equal(t, "deploy", tokens[0].DisplayName)
+	require.Equal(t, "sa1", tokens[0].ParentResourceId.Resource)
+	trait := secretTrait(t, tokens[0])
+	require.Equal(t, "sa1", trait.GetIdentityId().GetResource())
+	require.Equal(t, serviceAccountResourceType.Id, trait.GetIdentityId().GetResourceType())
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), trait.GetCreatedAt().AsTime())
+	require.Equal(t, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), trait.GetLastUsedAt().AsTime())
+	require.Equal(t, time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC), trait.GetExpiresAt().AsTime())
+
+	// A token never used and without expiry leaves those fields unset.
+	trait = secretTrait(t, tokens[1])
+	require.Equal(t, "sa1", trait.GetIdentityId().GetResource())
+	require.Nil(t, trait.GetCreatedAt())
+	require.Nil(t, trait.GetLastUsedAt())
+	require.Nil(t, trait.GetExpiresAt())
+}
@@ -0,0 +1,210 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Create a new connector resource for a tray.ai authentication.
+func authenticationResource(
+	_ context.Context,
+	auth client.Authentication,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":           auth.ID,
+		"name":         auth.Name,
+		"service_name": auth.ServiceName,
+		"owner_id":     auth.OwnerID,
+		"workspace_id": auth.WorkspaceID,
+	}
+
+	traitOptions := []resource.SecretTraitOption{
+		withSecretProfile(profile),
+	}
+	if auth.OwnerID != "" {
+		traitOptions = append(traitOptions, resource.WithSecretIdentityID(&v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     auth.OwnerID,
+		}))
+	}
+	if !auth.CreatedAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithSecretCreatedAt(auth.CreatedAt))
+	}
+	if !auth.LastUsedAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithSecretLastUsedAt(auth.LastUsedAt))
+	}
+
+	// Authentications of custom services may not name the service.
+	service := auth.ServiceName
+	if service == "" {
+		service = auth.Name
+	}
+
+	return resource.NewSecretResource(
+		auth.Name,
+		authenticationResourceType,
+		auth.ID,
+		traitOptions,
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(fmt.Sprintf("%s authentication", service)),
+	)
+}
+
+// withSecretProfile sets the profile of the secret trait.
+func withSecretProfile(profile map[string]interface{}) resource.SecretTraitOption {
+	return func(t *v2.SecretTrait) error {
+		p, err := structpb.NewStruct(profile)
+		if err != nil {
+			return err
+		}
+		t.Profile = p
+		return nil
+	}
+}
+
+type authenticationBuilder struct {
+	client *client.Client
+	// deleteOrphanedOnly restricts deletions to authentications whose owner is disabled or removed.
+	deleteOrphanedOnly bool
+}
+
+func (o *authenticationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return authenticationResourceType
+}
+
+// List returns the authentications of the parent workspace as resource objects.
+func (o *authenticationBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != workspaceResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	var (
+		authentications []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListAuthentications(ctx, client.ListAuthenticationsParams{
+		WorkspaceID: parentResourceID.Resource,
+		Cursor:      pToken.Token,
+		First:       pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListAuthentications failed: %w", err)
+	}
+
+	for _, auth := range resp.Authentications {
+		if auth.WorkspaceID == "" {
+			auth.WorkspaceID = parentResourceID.Resource
+		}
+		vAuth, err := authenticationResource(ctx, auth, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		authentications = append(authentications, vAuth)
+	}
+
+	return authentications, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns the owner entitlement of the authentication.
+func (o *authenticationBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			ownerEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Authentication owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Owns the %s authentication", resource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant of the authentication to the user who owns it.
+func (o *authenticationBuilder) Grants(ctx context.Context, res *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	annos := annotations.Annotations(res.Annotations)
+	secretTrait := &v2.SecretTrait{}
+	ok, err := annos.Pick(secretTrait)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: cannot get secret trait: %w", err)
+	}
+	if !ok || secretTrait.GetIdentityId() == nil {
+		return nil, "", nil, nil
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(res, ownerEntitlement, secretTrait.GetIdentityId()),
+	}, "", nil, nil
+}
+
+// Delete deletes the authentication, revoking the stored third-party credentials. Authentications that are
+// already gone are reported as deleted so retries stay safe.
+func (o *authenticationBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != authenticationResourceType.Id {
+		return nil, fmt.Errorf("baton-trayai: cannot delete non-authentication resource %s", resourceId.ResourceType)
+	}
+	authID := resourceId.Resource
+
+	if o.deleteOrphanedOnly {
+		auth, err := o.client.GetAuthentication(ctx, authID, client.WithoutCache())
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("baton-trayai: GetAuthentication failed: %w", err)
+		}
+		if err := o.ensureOwnerInactive(ctx, auth); err != nil {
+			return nil, err
+		}
+	}
+
+	err := o.client.DeleteAuthentication(ctx, authID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("baton-trayai: DeleteAuthentication failed: %w", err)
+	}
+	return nil, nil
+}
+
+// ensureOwnerInactive returns an error unless the owner of the authentication is disabled or removed. The owner
+// is read without the HTTP cache, so an owner re-enabled since the last sync is seen as active.
+func (o *authenticationBuilder) ensureOwnerInactive(ctx context.Context, auth *client.Authentication) error {
+	if auth.OwnerID == "" {
+		return nil
+	}
+
+	owner, err := o.client.GetUser(ctx, auth.OwnerID, client.WithoutCache())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		return fmt.Errorf("baton-trayai: GetUser failed: %w", err)
+	}
+	if owner == nil {
+		return status.Errorf(codes.Internal, "baton-trayai: tray.ai returned no body for user %s", auth.OwnerID)
+	}
+	if owner.Disabled {
+		return nil
+	}
+
+	return status.Errorf(codes.FailedPrecondition,
+		"baton-trayai: authentication %s is owned by the active user %s, only authentications of disabled or removed users can be deleted",
+		auth.ID, auth.OwnerID,
+	)
+}
+
+func newAuthenticationBuilder(c *client.Client, deleteOrphanedOnly bool) *authenticationBuilder {
+	return &authenticationBuilder{
+		client:             c,
+		deleteOrphanedOnly: deleteOrphanedOnly,
+	}
+}
@@ -0,0 +1,147 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// secretTrait returns the secret trait of a resource.
+func secretTrait(t *testing.T, res *v2.Resource) *v2.SecretTrait {
+	t.Helper()
+	trait := &v2.SecretTrait{}
+	annos := annotations.Annotations(res.Annotations)
+	ok, err := annos.Pick(trait)
+	require.NoError(t, err)
+	require.True(t, ok)
+	return trait
+}
+
+func TestListAuthentications(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/authentications", r.URL.Path)
+		require.Equal(t, "w1", r.URL.Query().Get("workspaceId"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "a1", "name": "Sales Salesforce", "serviceName": "salesforce", "userId": "u1", "workspaceId": "w9",
+				 "createdAt": "2024-05-01T10:00:00Z", "lastUsedAt": "2024-06-01T10:00:00Z"},
+				{"id": "a2", "name": "Internal API"}
+			],
+			"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newAuthenticationBuilder(c.client, true)
+
+	// Authentications are only listed under their workspace.
+	auths, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Empty(t, auths)
+
+	workspaceID := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "w1"}
+	auths, next, _, err := builder.List(ctx, workspaceID, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, auths, 2)
+	require.Equal(t, "c1", next)
+
+	require.Equal(t, "Sales Salesforce", auths[0].DisplayName)
+	require.Equal(t, "salesforce authentication", auths[0].Description)
+	trait := secretTrait(t, auths[0])
+	require.Equal(t, "u1", trait.GetIdentityId().GetResource())
+	require.Equal(t, userResourceType.Id, trait.GetIdentityId().GetResourceType())
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), trait.GetCreatedAt().AsTime())
+	require.Equal(t, time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC), trait.GetLastUsedAt().AsTime())
+	workspace, _ := resource.GetProfileStringValue(trait.GetProfile(), "workspace_id")
+	require.Equal(t, "w9", workspace)
+
+	// Without an owner, timestamps, service nor workspace, the authentication falls back to its name and the
+	// workspace it is listed under.
+	require.Equal(t, "Internal API authentication", auths[1].Description)
+	trait = secretTrait(t, auths[1])
+	require.Nil(t, trait.GetIdentityId())
+	require.Nil(t, trait.GetCreatedAt())
+	require.Nil(t, trait.GetLastUsedAt())
+	workspace, _ = resource.GetProfileStringValue(trait.GetProfile(), "workspace_id")
+	require.Equal(t, "w1", workspace)
+
+	grants, _, _, err := builder.Grants(ctx, auths[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "authentication:a1:owner", grants[0].Entitlement.Id)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+
+	grants, _, _, err = builder.Grants(ctx, auths[1], &pagination.Token{})
+	require.NoError(t, err)
+	require.Empty(t, grants)
+}
+
+func TestDeleteOrphanedAuthentication(t *testing.T) {
+	ctx := context.Background()
+	authID := &v2.ResourceId{ResourceType: authenticationResourceType.Id, Resource: "a1"}
+
+	newBuilder := func(t *testing.T, owner *string, deleted *bool) *authenticationBuilder {
+		// The owner check must not be answered from the HTTP cache the user sync fills.
+		c := newCachingTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/core/v1/authentications/a1" && r.Method == http.MethodGet:
+				_, _ = w.Write([]byte(`{"id": "a1", "name": "Salesforce", "userId": "u1"}`))
+			case r.URL.Path == "/core/v1/authentications/a1" && r.Method == http.MethodDelete:
+				*deleted = true
+				_, _ = w.Write([]byte(`{}`))
+			case r.URL.Path == "/core/v1/users/u1" && *owner != "":
+				_, _ = w.Write([]byte(*owner))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		return newAuthenticationBuilder(c.client, true)
+	}
+
+	t.Run("keeps the authentication of an active owner", func(t *testing.T) {
+		owner := `{"id": "u1", "disabled": true}`
+		deleted := false
+		builder := newBuilder(t, &owner, &deleted)
+
+		// The user sync saw the owner disabled, then the owner was re-enabled.
+		_, err := builder.client.GetUser(ctx, "u1")
+		require.NoError(t, err)
+		owner = `{"id": "u1", "disabled": false}`
+
+		_, err = builder.Delete(ctx, authID)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		require.False(t, deleted)
+	})
+
+	t.Run("deletes the authentication of a disabled owner", func(t *testing.T) {
+		owner := `{"id": "u1", "disabled": true}`
+		deleted := false
+		builder := newBuilder(t, &owner, &deleted)
+
+		_, err := builder.Delete(ctx, authID)
+		require.NoError(t, err)
+		require.True(t, deleted)
+	})
+
+	t.Run("deletes the authentication of a removed owner", func(t *testing.T) {
+		owner := ""
+		deleted := false
+		builder := newBuilder(t, &owner, &deleted)
+
+		_, err := builder.Delete(ctx, authID)
+		require.NoError(t, err)
+		require.True(t, deleted)
+	})
+}
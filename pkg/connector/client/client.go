@@ -1,31 +1,90 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"strings"
+	"time"
 
+	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/ratelimit"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 )
 
 // Params is the parameters used to init a tray.io client.
 type Params struct {
 	HttpClient *uhttp.BaseHttpClient
+	// BaseURL is the base URL of the tray.ai API of the tenant's region. Defaults to the US region.
+	BaseURL string
+	// GraphQLURL is the URL of the tray.ai Embedded GraphQL API. Defaults to the GraphQL URL of the region of
+	// BaseURL, or to /graphql on BaseURL when it isn't the API of a region.
+	GraphQLURL string
+	// PageSize is the page size of the list calls that don't request one. Defaults to DefaultPageSize.
+	PageSize int
+	// RateLimiter paces every request sent to tray.ai, including those sent WithoutCache. Requests aren't paced
+	// when nil. Set it here rather than with uhttp.WithRateLimiter, which only sees the cached requests.
+	RateLimiter ratelimit.Limiter
 }
 
 // Client is used to interact with Tray.io.
 type Client struct {
 	httpClient *uhttp.BaseHttpClient
+	baseURL    string
+	graphQLURL string
+	// defaultPageSize is the page size of the list calls that don't request one.
+	defaultPageSize int
+	// retryBaseDelay is the backoff before the first retry of a request, doubled on every following one.
+	retryBaseDelay time.Duration
+	rateLimiter    ratelimit.Limiter
 }
 
 // NewClient initializes a new tray.ai Client.
 func NewClient(p Params) *Client {
+	baseURL := strings.TrimSuffix(p.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultBaseURL
+	}
+	graphQLURL := p.GraphQLURL
+	if graphQLURL == "" {
+		graphQLURL = defaultGraphQLURL(baseURL)
+	}
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	rateLimiter := p.RateLimiter
+	if rateLimiter == nil {
+		rateLimiter = ratelimit.NewUnlimited()
+	}
 	return &Client{
-		httpClient: p.HttpClient,
+		httpClient:      p.HttpClient,
+		baseURL:         baseURL,
+		graphQLURL:      graphQLURL,
+		defaultPageSize: min(pageSize, MaxPageSize),
+		retryBaseDelay:  defaultRetryBaseDelay,
+		rateLimiter:     rateLimiter,
 	}
 }
 
+// defaultGraphQLURL returns the URL of the Embedded GraphQL API of the region whose API is at baseURL, or /graphql
+// on baseURL when it isn't the API of a region.
+func defaultGraphQLURL(baseURL string) string {
+	for region, regionBaseURL := range RegionBaseURLs {
+		if regionBaseURL == baseURL {
+			return RegionGraphQLURLs[region]
+		}
+	}
+	return baseURL + graphQLPath
+}
+
 // ListUsersParams is the params passed to ListUsers().
 type ListUsersParams struct {
 	Cursor string
@@ -41,48 +100,525 @@ type ListUsersResp struct {
 }
 
 // ListUsers list all the users from tray.ai.
-func (c *Client) ListUsers(ctx context.Context, params ListUsersParams) (*ListUsersResp, error) {
-	urlpath, err := url.Parse(basePath + listUsersPath)
-	if err != nil {
+func (c *Client) ListUsers(ctx context.Context, params ListUsersParams) (*ListUsersResp, annotations.Annotations, error) {
+	return listPage[ListUsersResp](ctx, c, listUsersPath, c.usersQuery(params))
+}
+
+// GetUser returns the details of a single tray.ai user, including their email.
+func (c *Client) GetUser(ctx context.Context, userID string, opts ...CallOption) (*User, error) {
+	var resp *User
+	path := fmt.Sprintf(getUserPath, url.PathEscape(userID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetUserUsage returns the login and task usage of a tray.ai user.
+func (c *Client) GetUserUsage(ctx context.Context, userID string) (*UserUsage, error) {
+	var resp *UserUsage
+	path := fmt.Sprintf(userUsagePath, url.PathEscape(userID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
 		return nil, err
 	}
+	return resp, nil
+}
 
-	urlpath.RawQuery = toQuery(urlpath, params)
+// CreateUserParams is the params passed to CreateUser().
+type CreateUserParams struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	// WorkspaceID and Role optionally add the new user to a workspace.
+	WorkspaceID string `json:"workspaceId,omitempty"`
+	Role        string `json:"role,omitempty"`
+}
 
-	req, err := c.httpClient.NewRequest(ctx,
-		http.MethodGet,
-		urlpath,
-		uhttp.WithAcceptJSONHeader(),
-		uhttp.WithContentTypeJSONHeader(),
-	)
+// CreateUser creates a new tray.ai user in the organization.
+func (c *Client) CreateUser(ctx context.Context, params CreateUserParams) (*User, error) {
+	var resp *User
+	if _, err := c.doRequest(ctx, http.MethodPost, listUsersPath, nil, params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteUser removes a user from the tray.ai organization.
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	path := fmt.Sprintf(getUserPath, url.PathEscape(userID))
+	_, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// ListWorkspacesParams is the params passed to ListWorkspaces().
+type ListWorkspacesParams struct {
+	Cursor string
+	First  int // page size.
+}
+
+// ListWorkspacesResp is the response returned from ListWorkspaces().
+type ListWorkspacesResp struct {
+	Workspaces []Workspace `json:"elements"`
+	Page       PageInfo    `json:"pageInfo"`
+}
+
+// ListWorkspaces list all the workspaces of the tray.ai organization.
+func (c *Client) ListWorkspaces(ctx context.Context, params ListWorkspacesParams) (*ListWorkspacesResp, annotations.Annotations, error) {
+	return listPage[ListWorkspacesResp](ctx, c, listWorkspacesPath, c.pageQuery(params.Cursor, params.First))
+}
+
+// GetWorkspace returns a single tray.ai workspace.
+func (c *Client) GetWorkspace(ctx context.Context, workspaceID string) (*Workspace, error) {
+	var resp *Workspace
+	path := fmt.Sprintf(workspacePath, url.PathEscape(workspaceID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
 
+// ListWorkspaceMembersParams is the params passed to ListWorkspaceMembers().
+type ListWorkspaceMembersParams struct {
+	WorkspaceID string
+	Cursor      string
+	First       int // page size.
+}
+
+// ListWorkspaceMembersResp is the response returned from ListWorkspaceMembers().
+type ListWorkspaceMembersResp struct {
+	Members []WorkspaceMember `json:"elements"`
+	Page    PageInfo          `json:"pageInfo"`
+}
+
+// ListWorkspaceMembers list the users of a tray.ai workspace along with their workspace role.
+func (c *Client) ListWorkspaceMembers(ctx context.Context, params ListWorkspaceMembersParams) (*ListWorkspaceMembersResp, annotations.Annotations, error) {
+	path := fmt.Sprintf(listWorkspaceMembersPath, url.PathEscape(params.WorkspaceID))
+	return listPage[ListWorkspaceMembersResp](ctx, c, path, c.pageQuery(params.Cursor, params.First))
+}
+
+// GetWorkspaceMember returns the membership of a user in a tray.ai workspace.
+func (c *Client) GetWorkspaceMember(ctx context.Context, workspaceID, userID string, opts ...CallOption) (*WorkspaceMember, error) {
+	var resp *WorkspaceMember
+	path := fmt.Sprintf(workspaceMemberPath, url.PathEscape(workspaceID), url.PathEscape(userID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// AddWorkspaceMember adds a user to a tray.ai workspace with the given role.
+func (c *Client) AddWorkspaceMember(ctx context.Context, workspaceID, userID, role string) error {
+	body := map[string]string{
+		"userId": userID,
+		"role":   role,
+	}
+	path := fmt.Sprintf(listWorkspaceMembersPath, url.PathEscape(workspaceID))
+	_, err := c.doRequest(ctx, http.MethodPost, path, nil, body, nil)
+	return err
+}
+
+// UpdateWorkspaceMemberRole replaces the role a user holds in a tray.ai workspace.
+func (c *Client) UpdateWorkspaceMemberRole(ctx context.Context, workspaceID, userID, role string) error {
+	body := map[string]string{
+		"role": role,
+	}
+	path := fmt.Sprintf(workspaceMemberPath, url.PathEscape(workspaceID), url.PathEscape(userID))
+	_, err := c.doRequest(ctx, http.MethodPatch, path, nil, body, nil)
+	return err
+}
+
+// RemoveWorkspaceMember removes a user from a tray.ai workspace.
+func (c *Client) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	path := fmt.Sprintf(workspaceMemberPath, url.PathEscape(workspaceID), url.PathEscape(userID))
+	_, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// ListProjectsParams is the params passed to ListProjects().
+type ListProjectsParams struct {
+	WorkspaceID string
+	Cursor      string
+	First       int // page size.
+}
+
+// ListProjectsResp is the response returned from ListProjects().
+type ListProjectsResp struct {
+	Projects []Project `json:"elements"`
+	Page     PageInfo  `json:"pageInfo"`
+}
+
+// ListProjects list the projects of a tray.ai workspace.
+func (c *Client) ListProjects(ctx context.Context, params ListProjectsParams) (*ListProjectsResp, annotations.Annotations, error) {
+	q := c.pageQuery(params.Cursor, params.First)
+	q.Set("workspaceId", params.WorkspaceID)
+	return listPage[ListProjectsResp](ctx, c, listProjectsPath, q)
+}
+
+// ListProjectCollaboratorsParams is the params passed to ListProjectCollaborators().
+type ListProjectCollaboratorsParams struct {
+	ProjectID string
+	Cursor    string
+	First     int // page size.
+}
+
+// ListProjectCollaboratorsResp is the response returned from ListProjectCollaborators().
+type ListProjectCollaboratorsResp struct {
+	Collaborators []ProjectCollaborator `json:"elements"`
+	Page          PageInfo              `json:"pageInfo"`
+}
+
+// ListProjectCollaborators list the users who collaborate on a tray.ai project along with their project role.
+func (c *Client) ListProjectCollaborators(ctx context.Context, params ListProjectCollaboratorsParams) (*ListProjectCollaboratorsResp, annotations.Annotations, error) {
+	path := fmt.Sprintf(listProjectCollaboratorsPath, url.PathEscape(params.ProjectID))
+	return listPage[ListProjectCollaboratorsResp](ctx, c, path, c.pageQuery(params.Cursor, params.First))
+}
+
+// ListSolutionsParams is the params passed to ListSolutions().
+type ListSolutionsParams struct {
+	Cursor string
+	First  int // page size.
+}
+
+// ListSolutionsResp is the response returned from ListSolutions().
+type ListSolutionsResp struct {
+	Solutions []Solution `json:"elements"`
+	Page      PageInfo   `json:"pageInfo"`
+}
+
+// ListSolutions list the Embedded solutions of the tray.ai organization.
+func (c *Client) ListSolutions(ctx context.Context, params ListSolutionsParams) (*ListSolutionsResp, annotations.Annotations, error) {
+	return listPage[ListSolutionsResp](ctx, c, listSolutionsPath, c.pageQuery(params.Cursor, params.First))
+}
+
+// ListWorkflowsParams is the params passed to ListWorkflows().
+type ListWorkflowsParams struct {
+	WorkspaceID string
+	// ProjectID limits the workflows to those of a project when set.
+	ProjectID string
+	Cursor    string
+	First     int // page size.
+}
+
+// ListWorkflowsResp is the response returned from ListWorkflows().
+type ListWorkflowsResp struct {
+	Workflows []Workflow `json:"elements"`
+	Page      PageInfo   `json:"pageInfo"`
+}
+
+// ListWorkflows list the workflows of a tray.ai workspace or project.
+func (c *Client) ListWorkflows(ctx context.Context, params ListWorkflowsParams) (*ListWorkflowsResp, annotations.Annotations, error) {
+	q := c.pageQuery(params.Cursor, params.First)
+	if params.WorkspaceID != "" {
+		q.Set("workspaceId", params.WorkspaceID)
+	}
+	if params.ProjectID != "" {
+		q.Set("projectId", params.ProjectID)
+	}
+	return listPage[ListWorkflowsResp](ctx, c, listWorkflowsPath, q)
+}
+
+// SetWorkflowEnabled enables or disables a tray.ai workflow.
+func (c *Client) SetWorkflowEnabled(ctx context.Context, workflowID string, enabled bool) error {
+	body := map[string]bool{
+		"enabled": enabled,
+	}
+	path := fmt.Sprintf(workflowPath, url.PathEscape(workflowID))
+	_, err := c.doRequest(ctx, http.MethodPatch, path, nil, body, nil)
+	return err
+}
+
+// TriggerCallableWorkflow starts a run of a tray.ai callable workflow with the given input.
+// The workflow runs asynchronously; its progress is reported by GetWorkflowExecution.
+func (c *Client) TriggerCallableWorkflow(ctx context.Context, workflowID string, input map[string]interface{}) (*WorkflowExecution, error) {
+	var resp *WorkflowExecution
+	body := map[string]interface{}{
+		"input": input,
+	}
+	path := fmt.Sprintf(triggerWorkflowPath, url.PathEscape(workflowID))
+	if _, err := c.doRequest(ctx, http.MethodPost, path, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetWorkflowExecution get the state of a run of a tray.ai workflow.
+func (c *Client) GetWorkflowExecution(ctx context.Context, executionID string, opts ...CallOption) (*WorkflowExecution, error) {
+	var resp *WorkflowExecution
+	path := fmt.Sprintf(workflowExecutionPath, url.PathEscape(executionID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListAuthenticationsParams is the params passed to ListAuthentications().
+type ListAuthenticationsParams struct {
+	WorkspaceID string
+	Cursor      string
+	First       int // page size.
+}
+
+// ListAuthenticationsResp is the response returned from ListAuthentications().
+type ListAuthenticationsResp struct {
+	Authentications []Authentication `json:"elements"`
+	Page            PageInfo         `json:"pageInfo"`
+}
+
+// ListAuthentications list the authentications (connected third-party credentials) of a tray.ai workspace.
+func (c *Client) ListAuthentications(ctx context.Context, params ListAuthenticationsParams) (*ListAuthenticationsResp, annotations.Annotations, error) {
+	q := c.pageQuery(params.Cursor, params.First)
+	q.Set("workspaceId", params.WorkspaceID)
+	return listPage[ListAuthenticationsResp](ctx, c, listAuthenticationsPath, q)
+}
+
+// GetAuthentication returns a single tray.ai authentication.
+func (c *Client) GetAuthentication(ctx context.Context, authenticationID string, opts ...CallOption) (*Authentication, error) {
+	var resp *Authentication
+	path := fmt.Sprintf(authenticationPath, url.PathEscape(authenticationID))
+	if _, err := c.doRequest(ctx, http.MethodGet, path, nil, nil, &resp, opts...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DeleteAuthentication deletes a tray.ai authentication, revoking the stored third-party credentials.
+func (c *Client) DeleteAuthentication(ctx context.Context, authenticationID string) error {
+	path := fmt.Sprintf(authenticationPath, url.PathEscape(authenticationID))
+	_, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// ListServiceAccountsParams is the params passed to ListServiceAccounts().
+type ListServiceAccountsParams struct {
+	Cursor string
+	First  int // page size.
+}
+
+// ListServiceAccountsResp is the response returned from ListServiceAccounts().
+type ListServiceAccountsResp struct {
+	ServiceAccounts []ServiceAccount `json:"elements"`
+	Page            PageInfo         `json:"pageInfo"`
+}
+
+// ListServiceAccounts list the service accounts of the tray.ai organization.
+func (c *Client) ListServiceAccounts(ctx context.Context, params ListServiceAccountsParams) (*ListServiceAccountsResp, annotations.Annotations, error) {
+	return listPage[ListServiceAccountsResp](ctx, c, listServiceAccountsPath, c.pageQuery(params.Cursor, params.First))
+}
+
+// ListServiceAccountTokensParams is the params passed to ListServiceAccountTokens().
+type ListServiceAccountTokensParams struct {
+	ServiceAccountID string
+	Cursor           string
+	First            int // page size.
+}
+
+// ListServiceAccountTokensResp is the response returned from ListServiceAccountTokens().
+type ListServiceAccountTokensResp struct {
+	Tokens []APIToken `json:"elements"`
+	Page   PageInfo   `json:"pageInfo"`
+}
+
+// ListServiceAccountTokens list the API tokens issued to a tray.ai service account.
+func (c *Client) ListServiceAccountTokens(
+	ctx context.Context,
+	params ListServiceAccountTokensParams,
+	opts ...CallOption,
+) (*ListServiceAccountTokensResp, annotations.Annotations, error) {
+	path := fmt.Sprintf(listServiceAccountTokensPath, url.PathEscape(params.ServiceAccountID))
+	return listPage[ListServiceAccountTokensResp](ctx, c, path, c.pageQuery(params.Cursor, params.First), opts...)
+}
+
+// CreateServiceAccountToken issues a new API token to a tray.ai service account. The returned token carries
+// its secret value, which tray.ai never returns again.
+func (c *Client) CreateServiceAccountToken(ctx context.Context, serviceAccountID, name string) (*CreatedAPIToken, error) {
+	var resp *CreatedAPIToken
+	body := map[string]string{
+		"name": name,
+	}
+	path := fmt.Sprintf(listServiceAccountTokensPath, url.PathEscape(serviceAccountID))
+	if _, err := c.doRequest(ctx, http.MethodPost, path, nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RevokeServiceAccountToken revokes an API token of a tray.ai service account.
+func (c *Client) RevokeServiceAccountToken(ctx context.Context, serviceAccountID, tokenID string) error {
+	path := fmt.Sprintf(serviceAccountTokenPath, url.PathEscape(serviceAccountID), url.PathEscape(tokenID))
+	_, err := c.doRequest(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
+
+// ListAuditLogsParams is the params passed to ListAuditLogs().
+type ListAuditLogsParams struct {
+	// From limits the entries to those that occurred at or after it when set.
+	From   time.Time
+	Cursor string
+	First  int // page size.
+}
+
+// ListAuditLogsResp is the response returned from ListAuditLogs().
+type ListAuditLogsResp struct {
+	Entries []AuditLogEntry `json:"elements"`
+	Page    PageInfo        `json:"pageInfo"`
+}
+
+// ListAuditLogs list the audit log entries of the tray.ai organization, oldest first.
+func (c *Client) ListAuditLogs(ctx context.Context, params ListAuditLogsParams) (*ListAuditLogsResp, annotations.Annotations, error) {
+	query := c.pageQuery(params.Cursor, params.First)
+	if !params.From.IsZero() {
+		query.Set("from", params.From.UTC().Format(time.RFC3339))
+	}
+	return listPage[ListAuditLogsResp](ctx, c, listAuditLogsPath, query)
+}
+
+// doRequest sends a request to the tray.ai API and decodes the JSON response into target.
+// body and target may be nil when the endpoint takes no payload or returns no content.
+// Throttled and failed requests are retried as described in retryDelay. The returned annotations carry the
+// rate-limit state reported by tray.ai so the SDK can pace the following requests.
+func (c *Client) doRequest(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body interface{},
+	target interface{},
+	opts ...CallOption,
+) (annotations.Annotations, error) {
+	return c.send(ctx, method != http.MethodPost, method, c.baseURL+path, query, body, target, opts...)
+}
+
+// CallOption changes how a single call to the tray.ai API is sent.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	noCache bool
+}
+
+// WithoutCache sends the call to tray.ai even when the HTTP cache holds its response. The SDK HTTP client answers
+// GET requests from a cache for up to an hour, so the reads whose result must be current use it: the state
+// provisioning decides from, or the progress of a workflow run.
+func WithoutCache() CallOption {
+	return func(o *callOptions) {
+		o.noCache = true
+	}
+}
+
+// doFunc sends an HTTP request the way uhttp.BaseHttpClient.Do does.
+type doFunc func(req *http.Request, options ...uhttp.DoOption) (*http.Response, error)
+
+// doUncached sends req like uhttp.BaseHttpClient.Do, without looking the response up in the cache nor storing it.
+// The SDK client offers no way to skip its cache for a single request. Pacing is applied by send, for cached and
+// uncached requests alike.
+func (c *Client) doUncached(req *http.Request, options ...uhttp.DoOption) (*http.Response, error) {
+	resp, err := c.httpClient.HttpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	var resp *ListUsersResp
-	rawResp, err := c.httpClient.Do(req, uhttp.WithJSONResponse(&resp))
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
 
-	defer rawResp.Body.Close()
-	return resp, nil
+	// The status code of the error is set from the response by newAPIError.
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp, uhttp.WrapErrorsWithRateLimitInfo(codes.Unknown, resp, fmt.Errorf("unexpected status code: %d", resp.StatusCode))
+	}
+
+	wresp := uhttp.WrapperResponse{
+		Header:     resp.Header,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Body:       body,
+	}
+	var errs []error
+	for _, option := range options {
+		if err := option(&wresp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return resp, errors.Join(errs...)
 }
 
-func toQuery(url *url.URL, p ListUsersParams) string {
-	q := url.Query()
-	if p.Cursor != "" {
-		q.Set("cursor", p.Cursor)
+// send sends a request to rawURL with do, as described in doRequest. Only idempotent requests are retried on
+// server errors.
+func (c *Client) send(
+	ctx context.Context,
+	idempotent bool,
+	method, rawURL string,
+	query url.Values,
+	body interface{},
+	target interface{},
+	opts ...CallOption,
+) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	var o callOptions
+	for _, opt := range opts {
+		opt(&o)
 	}
-	if p.First != 0 {
-		q.Set("first", strconv.Itoa(p.First))
+	var do doFunc = c.httpClient.Do
+	if o.noCache {
+		do = c.doUncached
 	}
-	if p.Last != 0 {
-		q.Set("last", strconv.Itoa(p.Last))
+
+	urlpath, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
 	}
-	if p.Email != "" {
-		q.Set("email", p.Email)
+	urlpath.RawQuery = query.Encode()
+
+	reqOpts := []uhttp.RequestOption{
+		uhttp.WithAcceptJSONHeader(),
+		uhttp.WithContentTypeJSONHeader(),
+	}
+	if body != nil {
+		reqOpts = append(reqOpts, uhttp.WithJSONBody(body))
+	}
+
+	var doOpts []uhttp.DoOption
+	if target != nil {
+		doOpts = append(doOpts, uhttp.WithJSONResponse(target))
+	}
+
+	for attempt := 0; ; attempt++ {
+		// The request body is consumed by every attempt, so the request is rebuilt each time.
+		req, err := c.httpClient.NewRequest(ctx, method, urlpath, reqOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		c.rateLimiter.Take()
+		rawResp, err := do(req, doOpts...)
+		if rawResp != nil {
+			rawResp.Body.Close()
+		}
+		if err == nil {
+			return rateLimitAnnotations(rawResp), nil
+		}
+
+		wait, retry := c.retryDelay(idempotent, rawResp, attempt)
+		if !retry {
+			if rawResp != nil && rawResp.StatusCode >= http.StatusBadRequest {
+				return nil, newAPIError(rawResp, err)
+			}
+			return nil, err
+		}
+		l.Debug("baton-trayai: retrying request",
+			zap.String("method", method),
+			zap.String("url", urlpath.Redacted()),
+			zap.Int("status_code", rawResp.StatusCode),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
 	}
-	return q.Encode()
 }
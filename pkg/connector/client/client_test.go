@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestClient returns a Client pointed at a local server running handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "true")
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(Params{
+		HttpClient: uhttp.NewBaseHttpClient(server.Client()),
+		BaseURL:    server.URL,
+	})
+}
+
+func TestListUsersUsesBaseURL(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, listUsersPath, r.URL.Path)
+		require.Equal(t, "abc", r.URL.Query().Get("cursor"))
+		require.Equal(t, "2", r.URL.Query().Get("first"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [{"id": "u1", "name": "Ada"}, {"id": "u2", "name": "Grace", "type": "external"}],
+			"pageInfo": {"endCursor": "def", "hasNextPage": true}
+		}`))
+	})
+
+	resp, _, err := c.ListUsers(context.Background(), ListUsersParams{Cursor: "abc", First: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Users, 2)
+	require.Equal(t, "u1", resp.Users[0].ID)
+	require.True(t, resp.Users[1].IsExternal())
+	require.True(t, resp.Page.HasNextPage)
+	require.Equal(t, "def", resp.Page.EndCursor)
+}
+
+func TestNewClientDefaultsToUSRegion(t *testing.T) {
+	c := NewClient(Params{})
+	require.Equal(t, RegionBaseURLs["us"], c.baseURL)
+
+	c = NewClient(Params{BaseURL: "https://api.eu1.tray.io/"})
+	require.Equal(t, "https://api.eu1.tray.io", c.baseURL)
+}
+
+func TestNewClientDefaultGraphQLURL(t *testing.T) {
+	require.Equal(t, "https://tray.io/graphql", NewClient(Params{}).graphQLURL)
+	for region, baseURL := range RegionBaseURLs {
+		require.Equal(t, RegionGraphQLURLs[region], NewClient(Params{BaseURL: baseURL}).graphQLURL, region)
+	}
+	require.Equal(t, "https://ap1.tray.io/graphql", NewClient(Params{BaseURL: "https://api.ap1.tray.io"}).graphQLURL)
+
+	// A custom region serves GraphQL next to its API unless graphql-url says otherwise.
+	require.Equal(t, "http://127.0.0.1:8080/graphql", NewClient(Params{BaseURL: "http://127.0.0.1:8080/"}).graphQLURL)
+	c := NewClient(Params{BaseURL: "https://api.eu1.tray.io", GraphQLURL: "https://embedded.example.com/graphql"})
+	require.Equal(t, "https://embedded.example.com/graphql", c.graphQLURL)
+}
+
+func TestDoRequestRetriesThrottledRequests(t *testing.T) {
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "42")
+		_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {}}`))
+	})
+
+	resp, annos, err := c.ListUsers(context.Background(), ListUsersParams{})
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	require.Len(t, resp.Users, 1)
+
+	rl := &v2.RateLimitDescription{}
+	ok, err := annos.Pick(rl)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, int64(100), rl.Limit)
+	require.Equal(t, int64(42), rl.Remaining)
+}
+
+func TestDoRequestGivesUp(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		status     int
+		retryAfter string
+		wantCalls  int
+	}{
+		{name: "server errors are retried a bounded number of times", method: http.MethodGet, status: http.StatusServiceUnavailable, wantCalls: maxRetries + 1},
+		{name: "server errors of POST are not retried", method: http.MethodPost, status: http.StatusInternalServerError, wantCalls: 1},
+		{name: "long Retry-After is left to the SDK", method: http.MethodGet, status: http.StatusTooManyRequests, retryAfter: "3600", wantCalls: 1},
+		{name: "client errors are not retried", method: http.MethodGet, status: http.StatusBadRequest, wantCalls: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(tc.status)
+			})
+			c.retryBaseDelay = time.Millisecond
+
+			_, err := c.doRequest(context.Background(), tc.method, listUsersPath, nil, nil, nil)
+			require.Error(t, err)
+			require.Equal(t, tc.wantCalls, calls)
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	wait, ok := parseRetryAfter("7", now)
+	require.True(t, ok)
+	require.Equal(t, 7*time.Second, wait)
+
+	wait, ok = parseRetryAfter("Wed, 01 May 2024 12:00:30 GMT", now)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, wait)
+
+	_, ok = parseRetryAfter("soon", now)
+	require.False(t, ok)
+}
+
+func TestAPIError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-123")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"code": "user_already_exists", "message": "A user with this email already exists"}`))
+	})
+
+	_, err := c.CreateUser(context.Background(), CreateUserParams{Email: "ada@example.com"})
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	require.Equal(t, "user_already_exists", apiErr.Code)
+	require.Equal(t, "A user with this email already exists", apiErr.Message)
+	require.Equal(t, "req-123", apiErr.RequestID)
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+	require.ErrorContains(t, err, "req-123")
+}
+
+func TestAPIErrorThrottled(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	_, _, err := c.ListUsers(context.Background(), ListUsersParams{})
+	// The SDK only waits and retries on Unavailable and DeadlineExceeded.
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	// The rate-limit details of the response are kept for the SDK to pace the sync.
+	st := status.Convert(err)
+	require.NotEmpty(t, st.Details())
+}
+
+func TestAPIErrorRequestTimeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestTimeout)
+	})
+
+	_, _, err := c.ListUsers(context.Background(), ListUsersParams{})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestPageSizePolicy(t *testing.T) {
+	var firsts []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		firsts = append(firsts, r.URL.Query().Get("first"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"elements": [], "pageInfo": {}}`))
+	})
+
+	for _, first := range []int{0, 10, 1000} {
+		_, _, err := c.ListWorkspaces(context.Background(), ListWorkspacesParams{First: first})
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{"50", "10", "100"}, firsts)
+}
+
+func TestListRestartsOnRejectedCursor(t *testing.T) {
+	var cursors []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+		w.Header().Set("Content-Type", "application/json")
+		if cursor != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code": "invalid_cursor", "message": "The cursor has expired"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {"endCursor": "fresh", "hasNextPage": true}}`))
+	})
+
+	resp, _, err := c.ListUsers(context.Background(), ListUsersParams{Cursor: "stale"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"stale", ""}, cursors)
+	// The cursors of the restarted listing are marked, but tray.ai is sent the cursor it issued.
+	require.Equal(t, "restarted:fresh", resp.Page.NextCursor())
+
+	// A listing restarts only once, its next rejected cursor fails it.
+	_, _, err = c.ListUsers(context.Background(), ListUsersParams{Cursor: resp.Page.NextCursor()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.ErrorContains(t, err, "expired again")
+	require.Equal(t, []string{"stale", "", "fresh"}, cursors)
+}
+
+func TestListKeepsOtherBadRequests(t *testing.T) {
+	var cursors []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		cursors = append(cursors, r.URL.Query().Get("cursor"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code": "invalid_filter", "message": "Unknown cursor field in filter"}`))
+	})
+
+	_, _, err := c.ListUsers(context.Background(), ListUsersParams{Cursor: "abc"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.Equal(t, []string{"abc"}, cursors)
+}
+
+// countingLimiter counts the requests it paces.
+type countingLimiter struct {
+	takes int
+}
+
+func (l *countingLimiter) Take() time.Time {
+	l.takes++
+	return time.Now()
+}
+
+func TestWithoutCache(t *testing.T) {
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "false")
+
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reads++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "u1"}`))
+	}))
+	defer server.Close()
+
+	limiter := &countingLimiter{}
+	c := NewClient(Params{
+		HttpClient:  uhttp.NewBaseHttpClient(server.Client()),
+		BaseURL:     server.URL,
+		RateLimiter: limiter,
+	})
+
+	ctx := context.Background()
+	for range 2 {
+		_, err := c.GetUser(ctx, "u1")
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, reads)
+
+	for range 2 {
+		_, err := c.GetUser(ctx, "u1", WithoutCache())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 3, reads)
+	// Cached and uncached requests are paced alike.
+	require.Equal(t, 4, limiter.takes)
+}
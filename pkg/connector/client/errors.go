@@ -0,0 +1,105 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDHeader is the header tray.ai identifies every request with. Quote it when contacting tray.ai support.
+const requestIDHeader = "X-Request-Id"
+
+// APIError is an error response of the tray.ai API.
+type APIError struct {
+	StatusCode int
+	// Code is the machine-readable error code of tray.ai, e.g. "user_already_exists".
+	Code      string
+	Message   string
+	RequestID string
+	// err is the error returned by the HTTP client, which carries the rate-limit details of the response.
+	err error
+}
+
+// errorBody is the JSON body of the tray.ai error responses.
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+// newAPIError builds an APIError from a failed response and the error the HTTP client returned for it.
+func newAPIError(resp *http.Response, err error) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(requestIDHeader),
+		err:        err,
+	}
+
+	var body errorBody
+	if raw, readErr := io.ReadAll(resp.Body); readErr == nil && json.Unmarshal(raw, &body) == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Message
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = body.RequestID
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("tray.ai API error %d", e.StatusCode)
+	if e.Code != "" {
+		msg += fmt.Sprintf(" (%s)", e.Code)
+	}
+	msg += ": " + e.Message
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" [request id %s]", e.RequestID)
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus maps the error onto a gRPC status so the SDK handles it like any other connector error. The
+// details of the HTTP client error, such as the rate-limit state, are kept.
+func (e *APIError) GRPCStatus() *status.Status {
+	p := status.Convert(e.err).Proto()
+	p.Code = int32(e.grpcCode())
+	p.Message = e.Error()
+	return status.FromProto(p)
+}
+
+func (e *APIError) grpcCode() codes.Code {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return codes.InvalidArgument
+	case e.StatusCode == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case e.StatusCode == http.StatusForbidden:
+		return codes.PermissionDenied
+	case e.StatusCode == http.StatusNotFound:
+		return codes.NotFound
+	case e.StatusCode == http.StatusConflict:
+		return codes.AlreadyExists
+	case e.StatusCode == http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case e.StatusCode == http.StatusTooManyRequests:
+		// The SDK waits and retries on Unavailable, pacing the sync from the rate-limit details of the error.
+		return codes.Unavailable
+	case e.StatusCode == http.StatusNotImplemented:
+		return codes.Unimplemented
+	case e.StatusCode >= 500:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The Embedded administration of tray.ai (external users, their solution instances and authentications) is only
+// exposed through the GraphQL API, which requires a partner master token. Its URL is set by Params.GraphQLURL.
+// For API documentation, see: https://developer.tray.ai/embedded/graphql-api/
+
+// graphQLRequest is the body of a GraphQL call.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// graphQLResponse is the body of a GraphQL response. Data is decoded into the caller's target.
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors GraphQLErrors   `json:"errors"`
+}
+
+// GraphQLError is an error returned by the tray.ai GraphQL API.
+type GraphQLError struct {
+	Message    string        `json:"message"`
+	Path       []interface{} `json:"path"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// GraphQLErrors are the errors of a GraphQL response. GraphQL reports failures with a 200 status, so the code
+// of the first error is mapped onto a gRPC status instead.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, gqlErr := range e {
+		msg := gqlErr.Message
+		if gqlErr.Extensions.Code != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, gqlErr.Extensions.Code)
+		}
+		msgs = append(msgs, msg)
+	}
+	return "tray.ai GraphQL error: " + strings.Join(msgs, "; ")
+}
+
+func (e GraphQLErrors) GRPCStatus() *status.Status {
+	code := codes.Unknown
+	if len(e) > 0 {
+		switch e[0].Extensions.Code {
+		case "UNAUTHENTICATED":
+			code = codes.Unauthenticated
+		case "FORBIDDEN":
+			code = codes.PermissionDenied
+		case "NOT_FOUND":
+			code = codes.NotFound
+		case "BAD_USER_INPUT", "GRAPHQL_VALIDATION_FAILED":
+			code = codes.InvalidArgument
+		case "RATE_LIMITED":
+			// The SDK waits and retries on Unavailable, as for a throttled REST call.
+			code = codes.Unavailable
+		}
+	}
+	return status.New(code, e.Error())
+}
+
+// relayConnection is a Relay-style paginated list of nodes.
+type relayConnection[T any] struct {
+	Edges []struct {
+		Node T `json:"node"`
+	} `json:"edges"`
+	PageInfo PageInfo `json:"pageInfo"`
+}
+
+func (c relayConnection[T]) nodes() []T {
+	nodes := make([]T, 0, len(c.Edges))
+	for _, edge := range c.Edges {
+		nodes = append(nodes, edge.Node)
+	}
+	return nodes
+}
+
+// pageInfoFields selects the Relay page info, which maps onto PageInfo.
+const pageInfoFields = `pageInfo { startCursor endCursor hasNextPage hasPreviousPage }`
+
+// pageVariables returns the Relay pagination variables of a query.
+func (c *Client) pageVariables(cursor string, first int) map[string]interface{} {
+	variables := map[string]interface{}{
+		"first": c.pageSize(first),
+	}
+	if cursor != "" {
+		variables["after"] = cursor
+	}
+	return variables
+}
+
+// doGraphQL runs a GraphQL query or mutation and decodes its data into target. Every operation is a POST, but
+// queries are retried on server errors like the other reads.
+func (c *Client) doGraphQL(ctx context.Context, query string, variables map[string]interface{}, target interface{}) (annotations.Annotations, error) {
+	var resp graphQLResponse
+	idempotent := !strings.HasPrefix(query, "mutation")
+	body := graphQLRequest{Query: query, Variables: variables}
+	annos, err := c.send(ctx, idempotent, http.MethodPost, c.graphQLURL, nil, body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return annos, resp.Errors
+	}
+	if target == nil || len(resp.Data) == 0 {
+		return annos, nil
+	}
+	if err := json.Unmarshal(resp.Data, target); err != nil {
+		return annos, fmt.Errorf("cannot decode GraphQL data: %w", err)
+	}
+	return annos, nil
+}
+
+// embeddedSolutionInstance is a solution instance as returned by the GraphQL API.
+type embeddedSolutionInstance struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Enabled  bool      `json:"enabled"`
+	Created  time.Time `json:"created"`
+	Owner    string    `json:"owner"`
+	Solution struct {
+		ID string `json:"id"`
+	} `json:"solution"`
+}
+
+func (i embeddedSolutionInstance) toSolutionInstance() SolutionInstance {
+	return SolutionInstance{
+		ID:         i.ID,
+		Name:       i.Name,
+		SolutionID: i.Solution.ID,
+		OwnerID:    i.Owner,
+		Enabled:    i.Enabled,
+		CreatedAt:  i.Created,
+	}
+}
+
+const solutionInstanceFields = `id name enabled created owner solution { id }`
+
+// ListEmbeddedSolutionInstancesParams is the params passed to ListEmbeddedSolutionInstances().
+type ListEmbeddedSolutionInstancesParams struct {
+	// SolutionID limits the instances to those of a solution when set.
+	SolutionID string
+	// OwnerID limits the instances to those of an external user when set.
+	OwnerID string
+	Cursor  string
+	First   int // page size.
+}
+
+// ListEmbeddedSolutionInstancesResp is the response returned from ListEmbeddedSolutionInstances().
+type ListEmbeddedSolutionInstancesResp struct {
+	SolutionInstances []SolutionInstance
+	Page              PageInfo
+}
+
+const listEmbeddedSolutionInstancesQuery = `query ListSolutionInstances($solutionId: ID, $owner: String, $first: Int, $after: String) {
+	solutionInstances(criteria: { solutionId: $solutionId, owner: $owner }, first: $first, after: $after) {
+		edges { node { ` + solutionInstanceFields + ` } }
+		` + pageInfoFields + `
+	}
+}`
+
+// ListEmbeddedSolutionInstances list the solution instances of the Embedded end-customer users.
+func (c *Client) ListEmbeddedSolutionInstances(
+	ctx context.Context,
+	params ListEmbeddedSolutionInstancesParams,
+) (*ListEmbeddedSolutionInstancesResp, annotations.Annotations, error) {
+	variables := c.pageVariables(params.Cursor, params.First)
+	if params.SolutionID != "" {
+		variables["solutionId"] = params.SolutionID
+	}
+	if params.OwnerID != "" {
+		variables["owner"] = params.OwnerID
+	}
+
+	var data struct {
+		SolutionInstances relayConnection[embeddedSolutionInstance] `json:"solutionInstances"`
+	}
+	annos, err := c.doGraphQL(ctx, listEmbeddedSolutionInstancesQuery, variables, &data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := data.SolutionInstances.nodes()
+	instances := make([]SolutionInstance, 0, len(nodes))
+	for _, node := range nodes {
+		instances = append(instances, node.toSolutionInstance())
+	}
+	return &ListEmbeddedSolutionInstancesResp{
+		SolutionInstances: instances,
+		Page:              data.SolutionInstances.PageInfo,
+	}, annos, nil
+}
+
+const updateSolutionInstanceMutation = `mutation UpdateSolutionInstance($solutionInstanceId: ID!, $enabled: Boolean!) {
+	updateSolutionInstance(input: { solutionInstanceId: $solutionInstanceId, enabled: $enabled }) {
+		solutionInstance { ` + solutionInstanceFields + ` }
+	}
+}`
+
+// SetSolutionInstanceEnabled enables or disables an Embedded solution instance.
+func (c *Client) SetSolutionInstanceEnabled(ctx context.Context, solutionInstanceID string, enabled bool) (*SolutionInstance, error) {
+	var data struct {
+		UpdateSolutionInstance struct {
+			SolutionInstance embeddedSolutionInstance `json:"solutionInstance"`
+		} `json:"updateSolutionInstance"`
+	}
+	variables := map[string]interface{}{
+		"solutionInstanceId": solutionInstanceID,
+		"enabled":            enabled,
+	}
+	if _, err := c.doGraphQL(ctx, updateSolutionInstanceMutation, variables, &data); err != nil {
+		return nil, err
+	}
+	instance := data.UpdateSolutionInstance.SolutionInstance.toSolutionInstance()
+	return &instance, nil
+}
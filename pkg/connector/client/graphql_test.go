@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newFakeGraphQLClient returns a Client pointed at a local GraphQL server that answers each request with the
+// result of respond.
+func newFakeGraphQLClient(t *testing.T, respond func(req graphQLRequest) string) *Client {
+	t.Helper()
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, graphQLPath, r.URL.Path)
+		require.Equal(t, http.MethodPost, r.Method)
+
+		var req graphQLRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(respond(req)))
+	})
+}
+
+func TestListEmbeddedSolutionInstancesPaginates(t *testing.T) {
+	c := newFakeGraphQLClient(t, func(req graphQLRequest) string {
+		require.True(t, strings.HasPrefix(req.Query, "query ListSolutionInstances"))
+		require.Equal(t, "s1", req.Variables["solutionId"])
+		require.EqualValues(t, 1, req.Variables["first"])
+
+		if req.Variables["after"] == nil {
+			return `{"data": {"solutionInstances": {
+				"edges": [{"node": {"id": "si1", "name": "Acme Sync", "enabled": true, "owner": "u1", "solution": {"id": "s1"}}}],
+				"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+			}}}`
+		}
+		require.Equal(t, "c1", req.Variables["after"])
+		return `{"data": {"solutionInstances": {
+			"edges": [{"node": {"id": "si2", "name": "Globex Sync", "owner": "u2", "solution": {"id": "s1"}}}],
+			"pageInfo": {"endCursor": "c2", "hasNextPage": false}
+		}}}`
+	})
+
+	var owners []string
+	cursor := ""
+	for {
+		resp, _, err := c.ListEmbeddedSolutionInstances(context.Background(), ListEmbeddedSolutionInstancesParams{
+			SolutionID: "s1",
+			Cursor:     cursor,
+			First:      1,
+		})
+		require.NoError(t, err)
+		for _, instance := range resp.SolutionInstances {
+			require.Equal(t, "s1", instance.SolutionID)
+			owners = append(owners, instance.OwnerID)
+		}
+		if cursor = resp.Page.NextCursor(); cursor == "" {
+			break
+		}
+	}
+	require.Equal(t, []string{"u1", "u2"}, owners)
+}
+
+func TestSetSolutionInstanceEnabled(t *testing.T) {
+	c := newFakeGraphQLClient(t, func(req graphQLRequest) string {
+		require.True(t, strings.HasPrefix(req.Query, "mutation UpdateSolutionInstance"))
+		require.Equal(t, "si1", req.Variables["solutionInstanceId"])
+		require.Equal(t, false, req.Variables["enabled"])
+		return `{"data": {"updateSolutionInstance": {"solutionInstance": {
+			"id": "si1", "name": "Acme Sync", "enabled": false, "owner": "u1", "solution": {"id": "s1"}
+		}}}}`
+	})
+
+	instance, err := c.SetSolutionInstanceEnabled(context.Background(), "si1", false)
+	require.NoError(t, err)
+	require.Equal(t, "s1", instance.SolutionID)
+	require.Equal(t, "u1", instance.OwnerID)
+	require.False(t, instance.Enabled)
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	c := newFakeGraphQLClient(t, func(req graphQLRequest) string {
+		return `{"data": null, "errors": [{"message": "Solution instance not found", "path": ["updateSolutionInstance"], "extensions": {"code": "NOT_FOUND"}}]}`
+	})
+
+	_, err := c.SetSolutionInstanceEnabled(context.Background(), "missing", false)
+	require.ErrorContains(t, err, "Solution instance not found")
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGraphQLRetries(t *testing.T) {
+	var calls int
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"solutionInstances": {"edges": [], "pageInfo": {}}}}`))
+	})
+	c.retryBaseDelay = time.Millisecond
+
+	// Queries are reads, so they are retried on server errors although they are sent as POST.
+	_, _, err := c.ListEmbeddedSolutionInstances(context.Background(), ListEmbeddedSolutionInstancesParams{})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+
+	// Mutations are not, since they may have been applied.
+	calls = 0
+	_, err = c.SetSolutionInstanceEnabled(context.Background(), "si1", false)
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestGraphQLURL(t *testing.T) {
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "true")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/embedded/graphql", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"solutionInstances": {"edges": [], "pageInfo": {}}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewClient(Params{
+		HttpClient: uhttp.NewBaseHttpClient(server.Client()),
+		BaseURL:    "https://api.example.com",
+		GraphQLURL: server.URL + "/embedded/graphql",
+	})
+	_, _, err := c.ListEmbeddedSolutionInstances(context.Background(), ListEmbeddedSolutionInstancesParams{})
+	require.NoError(t, err)
+}
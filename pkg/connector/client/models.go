@@ -1,17 +1,231 @@
 package client
 
+import (
+	"strings"
+	"time"
+)
+
 // User is the Tray.ai user.
 type User struct {
 	ID               string `json:"id"`
 	Name             string `json:"name"`
+	Email            string `json:"email"`
 	Type             string `json:"type"`
 	Description      string `json:"description"`
 	MonthlyTaskLimit int64  `json:"monthlyTaskLimit"`
+	Disabled         bool   `json:"disabled"`
+	ExternalUserID   string `json:"externalUserId"`
+	OrgRole          string `json:"organizationRole"`
+}
+
+// UserUsage is the login and task usage of a Tray.ai user. TasksUsed counts the tasks run in the current
+// billing month, against the user's MonthlyTaskLimit.
+type UserUsage struct {
+	LastLoginAt    time.Time `json:"lastLoginAt"`
+	LastActivityAt time.Time `json:"lastActivityAt"`
+	TasksUsed      int64     `json:"tasksUsed"`
+}
+
+// The types of Tray.ai users.
+const (
+	// UserTypeMember is a member of the organization.
+	UserTypeMember = "member"
+	// UserTypeExternal is an end-customer user created through Tray.ai Embedded.
+	UserTypeExternal = "external"
+)
+
+// The organization-wide roles a Tray.ai user can hold.
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// IsExternal reports whether the user is an Embedded end-customer user rather than an organization member.
+func (u User) IsExternal() bool {
+	return strings.EqualFold(u.Type, UserTypeExternal)
+}
+
+// Project is a Tray.ai project, a group of workflows inside a workspace.
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	WorkspaceID string `json:"workspaceId"`
+}
+
+// ProjectCollaborator is a user who collaborates on a Tray.ai project along with the role they hold in it.
+type ProjectCollaborator struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// The roles a user can hold in a Tray.ai project.
+const (
+	ProjectRoleOwner  = "owner"
+	ProjectRoleEditor = "editor"
+	ProjectRoleViewer = "viewer"
+)
+
+// Workflow is a Tray.ai workflow, the automation logic run by tray.ai. It belongs to a workspace and optionally
+// to one of its projects.
+type Workflow struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	WorkspaceID    string    `json:"workspaceId"`
+	ProjectID      string    `json:"projectId"`
+	Enabled        bool      `json:"enabled"`
+	TriggerType    string    `json:"triggerType"`
+	Connectors     []string  `json:"connectors"`
+	CreatedBy      string    `json:"createdBy"`
+	LastModifiedBy string    `json:"lastModifiedBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// WorkflowExecution is a run of a Tray.ai workflow.
+type WorkflowExecution struct {
+	ID         string                 `json:"id"`
+	WorkflowID string                 `json:"workflowId"`
+	Status     string                 `json:"status"`
+	Error      string                 `json:"error"`
+	Output     map[string]interface{} `json:"output"`
+	StartedAt  time.Time              `json:"startedAt"`
+	FinishedAt time.Time              `json:"finishedAt"`
 }
 
+// The statuses of a Tray.ai workflow execution.
+const (
+	WorkflowExecutionPending   = "pending"
+	WorkflowExecutionRunning   = "running"
+	WorkflowExecutionSucceeded = "succeeded"
+	WorkflowExecutionFailed    = "failed"
+	WorkflowExecutionCancelled = "cancelled"
+)
+
+// Solution is a Tray.ai Embedded solution, an integration template end-customers deploy as instances.
+type Solution struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// SolutionInstance is a Tray.ai Embedded solution deployed for, and owned by, an external user. Solution
+// instances are only exposed by the GraphQL API, whose shape is decoded by embeddedSolutionInstance.
+type SolutionInstance struct {
+	ID         string
+	Name       string
+	SolutionID string
+	OwnerID    string
+	Enabled    bool
+	CreatedAt  time.Time
+}
+
+// Authentication is a Tray.ai authentication, the stored credentials of a third-party service such as an
+// OAuth token or an API key.
+type Authentication struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ServiceName string    `json:"serviceName"`
+	OwnerID     string    `json:"userId"`
+	WorkspaceID string    `json:"workspaceId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastUsedAt  time.Time `json:"lastUsedAt"`
+}
+
+// ServiceAccount is a Tray.ai service account, a non-human identity used to call the tray.ai API.
+type ServiceAccount struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     string    `json:"ownerId"`
+	Disabled    bool      `json:"disabled"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// APIToken is a Tray.ai API token issued to a service account.
+type APIToken struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// CreatedAPIToken is a newly issued Tray.ai API token along with its secret value.
+type CreatedAPIToken struct {
+	APIToken
+	Token string `json:"token"`
+}
+
+// AuditLogEntry is an entry of the Tray.ai audit log.
+type AuditLogEntry struct {
+	ID           string    `json:"id"`
+	EventType    string    `json:"eventType"`
+	OccurredAt   time.Time `json:"timestamp"`
+	ActorID      string    `json:"actorId"`
+	TargetUserID string    `json:"targetUserId"`
+	WorkspaceID  string    `json:"workspaceId"`
+	Role         string    `json:"role"`
+	PreviousRole string    `json:"previousRole"`
+}
+
+// The audit log event types the connector turns into Baton events.
+const (
+	AuditEventUserLogin                = "user.login"
+	AuditEventWorkspaceUserAdded       = "workspace.user.added"
+	AuditEventWorkspaceUserRemoved     = "workspace.user.removed"
+	AuditEventWorkspaceUserRoleUpdated = "workspace.user.role_updated"
+)
+
+// PageInfo is the position of a page in a tray.ai listing. The SDK only walks listings forward, so the
+// connector pages with EndCursor and HasNextPage; StartCursor and HasPreviousPage are kept for completeness.
 type PageInfo struct {
 	StartCursor     string `json:"startCursor"`
 	EndCursor       string `json:"endCursor"`
 	HasNextPage     bool   `json:"hasNextPage"`
 	HasPreviousPage bool   `json:"hasPreviousPage"`
+	// restarted is set on the pages of a listing that restarted from its first page, see listPage.
+	restarted bool
 }
+
+// NextCursor returns the cursor of the page after this one, or "" on the last page. A next page without a
+// cursor is treated as the last one, since an empty cursor would list from the first page again.
+func (p PageInfo) NextCursor() string {
+	if !p.HasNextPage || p.EndCursor == "" {
+		return ""
+	}
+	if p.restarted {
+		return restartedCursorPrefix + p.EndCursor
+	}
+	return p.EndCursor
+}
+
+// Workspace is the Tray.ai workspace.
+type Workspace struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// WorkspaceMember is a user of a Tray.ai workspace along with the role they hold in it.
+type WorkspaceMember struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// The roles a user can hold in a Tray.ai workspace.
+const (
+	WorkspaceRoleOwner       = "owner"
+	WorkspaceRoleAdmin       = "admin"
+	WorkspaceRoleContributor = "contributor"
+	WorkspaceRoleViewer      = "viewer"
+)
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultPageSize is the page size of the list calls when none is requested nor configured.
+	DefaultPageSize = 50
+	// MaxPageSize is the largest page tray.ai returns. Larger requests are clamped to it.
+	MaxPageSize = 100
+)
+
+// pageSize applies the page-size policy to a requested size: unset sizes fall back to the configured page size
+// and sizes above what tray.ai accepts are clamped.
+func (c *Client) pageSize(requested int) int {
+	if requested <= 0 {
+		requested = c.defaultPageSize
+	}
+	return min(requested, MaxPageSize)
+}
+
+// pageQuery encodes the cursor pagination params shared by the tray.ai list endpoints.
+func (c *Client) pageQuery(cursor string, first int) url.Values {
+	q := url.Values{}
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	q.Set("first", strconv.Itoa(c.pageSize(first)))
+	return q
+}
+
+// usersQuery encodes the params of ListUsers.
+func (c *Client) usersQuery(p ListUsersParams) url.Values {
+	q := c.pageQuery(p.Cursor, p.First)
+	if p.Last != 0 {
+		q.Set("last", strconv.Itoa(p.Last))
+	}
+	if p.Email != "" {
+		q.Set("email", p.Email)
+	}
+	return q
+}
+
+// restartedCursorPrefix marks the cursors of a listing that already restarted from its first page. The SDK
+// checkpoints the cursors, so the mark survives a restarted sync.
+const restartedCursorPrefix = "restarted:"
+
+// invalidCursorCode is the error code of tray.ai rejecting the cursor of a list call, e.g. once it expired.
+const invalidCursorCode = "invalid_cursor"
+
+// pageResponse is a response of a tray.ai list endpoint, R, through a pointer.
+type pageResponse[R any] interface {
+	*R
+	page() *PageInfo
+}
+
+// listPage fetches a page of a tray.ai list endpoint into a response of type R.
+//
+// The cursors are checkpointed by the SDK, so a sync restarted long after it stopped may resume with a cursor
+// tray.ai expired. Rather than failing every attempt of the sync, the listing then starts over from the first
+// page once; the resources already synced are simply synced again. The cursors of the restarted listing are
+// marked, and a listing whose cursor expires again fails: it takes longer than tray.ai keeps its cursors
+// valid, so restarting it again would never complete.
+func listPage[R any, P pageResponse[R]](ctx context.Context, c *Client, path string, query url.Values, opts ...CallOption) (*R, annotations.Annotations, error) {
+	cursor, restarted := strings.CutPrefix(query.Get("cursor"), restartedCursorPrefix)
+	if restarted {
+		query.Set("cursor", cursor)
+	}
+
+	var resp *R
+	annos, err := c.doRequest(ctx, http.MethodGet, path, query, nil, &resp, opts...)
+	if err != nil && cursor != "" && isInvalidCursor(err) {
+		if restarted {
+			return nil, nil, fmt.Errorf("baton-trayai: the page cursor of %s expired again after the listing restarted from the first page: %w", path, err)
+		}
+		ctxzap.Extract(ctx).Warn("baton-trayai: the page cursor was rejected, listing from the first page",
+			zap.String("path", path),
+			zap.Error(err),
+		)
+		query.Del("cursor")
+		restarted = true
+		annos, err = c.doRequest(ctx, http.MethodGet, path, query, nil, &resp, opts...)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp != nil {
+		P(resp).page().restarted = restarted
+	}
+	return resp, annos, nil
+}
+
+// isInvalidCursor returns whether err is tray.ai rejecting the cursor of a list call.
+func isInvalidCursor(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest && apiErr.Code == invalidCursorCode
+}
+
+func (r *ListUsersResp) page() *PageInfo                { return &r.Page }
+func (r *ListWorkspacesResp) page() *PageInfo           { return &r.Page }
+func (r *ListWorkspaceMembersResp) page() *PageInfo     { return &r.Page }
+func (r *ListProjectsResp) page() *PageInfo             { return &r.Page }
+func (r *ListProjectCollaboratorsResp) page() *PageInfo { return &r.Page }
+func (r *ListWorkflowsResp) page() *PageInfo            { return &r.Page }
+func (r *ListSolutionsResp) page() *PageInfo            { return &r.Page }
+func (r *ListAuthenticationsResp) page() *PageInfo      { return &r.Page }
+func (r *ListServiceAccountsResp) page() *PageInfo      { return &r.Page }
+func (r *ListServiceAccountTokensResp) page() *PageInfo { return &r.Page }
+func (r *ListAuditLogsResp) page() *PageInfo            { return &r.Page }
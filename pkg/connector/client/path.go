@@ -2,6 +2,41 @@ package client
 
 // For API documentation, see: https://developer.tray.ai/openapi/trayapi/tag/overview/
 const (
-	basePath      = "https://api.tray.io"
-	listUsersPath = "/core/v1/users"
+	defaultBaseURL               = "https://api.tray.io"
+	listUsersPath                = "/core/v1/users"
+	getUserPath                  = "/core/v1/users/%s"
+	userUsagePath                = "/core/v1/users/%s/usage"
+	listWorkspacesPath           = "/core/v1/workspaces"
+	workspacePath                = "/core/v1/workspaces/%s"
+	listWorkspaceMembersPath     = "/core/v1/workspaces/%s/users"
+	workspaceMemberPath          = "/core/v1/workspaces/%s/users/%s"
+	listProjectsPath             = "/core/v1/projects"
+	listProjectCollaboratorsPath = "/core/v1/projects/%s/collaborators"
+	listWorkflowsPath            = "/core/v1/workflows"
+	workflowPath                 = "/core/v1/workflows/%s"
+	triggerWorkflowPath          = "/core/v1/workflows/%s/trigger"
+	workflowExecutionPath        = "/core/v1/workflow-executions/%s"
+	listSolutionsPath            = "/core/v1/solutions"
+	listAuthenticationsPath      = "/core/v1/authentications"
+	authenticationPath           = "/core/v1/authentications/%s"
+	listServiceAccountsPath      = "/core/v1/service-accounts"
+	listServiceAccountTokensPath = "/core/v1/service-accounts/%s/tokens"
+	serviceAccountTokenPath      = "/core/v1/service-accounts/%s/tokens/%s"
+	listAuditLogsPath            = "/core/v1/audit-logs"
+	graphQLPath                  = "/graphql"
 )
+
+// RegionBaseURLs maps each tray.ai region to the base URL of its API.
+var RegionBaseURLs = map[string]string{
+	"us":   "https://api.tray.io",
+	"eu":   "https://api.eu1.tray.io",
+	"apac": "https://api.ap1.tray.io",
+}
+
+// RegionGraphQLURLs maps each tray.ai region to the URL of its Embedded GraphQL API, which isn't served by the
+// API host of the region.
+var RegionGraphQLURLs = map[string]string{
+	"us":   "https://tray.io/graphql",
+	"eu":   "https://eu1.tray.io/graphql",
+	"apac": "https://ap1.tray.io/graphql",
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/ratelimit"
+)
+
+const (
+	// maxRetries is the number of times a throttled or failed request is retried before giving up.
+	maxRetries = 3
+	// maxRetryWait caps the wait before a retry. When tray.ai asks to wait longer, the error is returned right
+	// away and the SDK paces the sync from the rate-limit details it carries.
+	maxRetryWait          = 30 * time.Second
+	defaultRetryBaseDelay = 500 * time.Millisecond
+)
+
+// retryDelay returns how long to wait before retrying a request that got resp back, and whether it should be
+// retried at all. Throttled requests are always retried. Server errors are only retried for idempotent
+// requests: every method but POST, which could create the same object twice, and GraphQL queries.
+func (c *Client) retryDelay(idempotent bool, resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp == nil || attempt >= maxRetries {
+		return 0, false
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented && idempotent:
+	default:
+		return 0, false
+	}
+
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+		if wait > maxRetryWait {
+			return 0, false
+		}
+		return wait, true
+	}
+
+	// Exponential backoff with jitter, so concurrent syncs don't retry in lockstep.
+	backoff := min(c.retryBaseDelay<<attempt, maxRetryWait)
+	return backoff/2 + rand.N(backoff/2+1), true //nolint:gosec // jitter only, not security-sensitive
+}
+
+// parseRetryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitAnnotations returns the rate-limit state of the tray.ai headers of resp, or nil if it has none.
+func rateLimitAnnotations(resp *http.Response) annotations.Annotations {
+	if resp == nil {
+		return nil
+	}
+	rl, err := ratelimit.ExtractRateLimitData(resp.StatusCode, &resp.Header)
+	// Malformed headers are ignored rather than failing a request that succeeded.
+	if err != nil || rl == nil || (rl.Limit == 0 && rl.Remaining == 0) {
+		return nil
+	}
+	if rl.Status == v2.RateLimitDescription_STATUS_UNSPECIFIED {
+		rl.Status = v2.RateLimitDescription_STATUS_OK
+	}
+	return annotations.New(rl)
+}
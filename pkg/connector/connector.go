@@ -2,25 +2,80 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	trayclient "github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Connector struct {
-	client *trayclient.Client
+	client                  *trayclient.Client
+	userOptions             userBuilderOptions
+	deleteOrphanedAuthsOnly bool
+	resourceTypes           resourceTypeSet
+	workspaceFilter         workspaceFilter
+}
+
+// Config holds the options the connector is built with.
+type Config struct {
+	AuthToken string
+	// BaseURL is the base URL of the tray.ai API of the tenant's region.
+	BaseURL string
+	// GraphQLURL is the URL of the tray.ai Embedded GraphQL API. Defaults to the GraphQL URL of the region of
+	// BaseURL.
+	GraphQLURL string
+	// ExcludeExternalUsers skips the Embedded end-customer users when syncing users.
+	ExcludeExternalUsers bool
+	// SuccessorUserID is the user who takes over the workspaces owned by a deleted user. Solutions are owned by the
+	// organization and need no handover.
+	SuccessorUserID string
+	// SyncUserUsage fetches the last login and task usage of every synced user, one extra call per user.
+	SyncUserUsage bool
+	// DeleteOrphanedAuthenticationsOnly restricts authentication deletions to those whose owner is disabled or
+	// removed.
+	DeleteOrphanedAuthenticationsOnly bool
+	// PageSize is the page size of the list calls the SDK doesn't set one for.
+	PageSize int
+	// Filters scopes the sync to some resource types and workspaces.
+	Filters Filters
+	// SyncUsers limits the user sync to these users, given by email or ID. All the users are synced when empty.
+	// Only users can be synced when set, see Filters.ValidateSyncUsers.
+	SyncUsers []string
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
+// Only the resource types selected by the filters are synced.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		newUserBuilder(d.client),
+	builders := []connectorbuilder.ResourceSyncer{
+		newUserBuilder(d.client, d.userOptions),
+		newWorkspaceBuilder(d.client, d.workspaceFilter, d.resourceTypes.children(projectResourceType, workflowResourceType, authenticationResourceType)),
+		newOrganizationBuilder(d.client),
+		newProjectBuilder(d.client, d.resourceTypes.children(workflowResourceType)),
+		newWorkflowBuilder(d.client, d.resourceTypes.contains(projectResourceType)),
+		newSolutionBuilder(d.client, d.resourceTypes.children(solutionInstanceResourceType)),
+		newSolutionInstanceBuilder(d.client),
+		newAuthenticationBuilder(d.client, d.deleteOrphanedAuthsOnly),
+		newServiceAccountBuilder(d.client, d.resourceTypes.children(apiTokenResourceType)),
+		newAPITokenBuilder(d.client),
+	}
+
+	syncers := make([]connectorbuilder.ResourceSyncer, 0, len(builders))
+	for _, builder := range builders {
+		if d.resourceTypes.contains(builder.ResourceType(ctx)) {
+			syncers = append(syncers, builder)
+		}
 	}
+	return syncers
 }
 
 // Asset takes an input AssetRef and attempts to fetch it using the connector's authenticated http client
@@ -33,19 +88,84 @@ func (d *Connector) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.R
 func (d *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
 		DisplayName: "Tray.ai",
-		Description: "Connector syncing users from tray.ai to Baton",
+		Description: "Connector syncing users, service accounts, organization roles, workspaces, projects, workflows, solutions and authentications from tray.ai to Baton",
 	}, nil
 }
 
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
 // to be sure that they are valid.
+//
+// Listing the organization users requires a master token, so a token that can only list workspaces is reported as
+// a user token: it can sync workspaces but not the org users, org roles or account provisioning. Validation fails
+// when such a token is used to sync users or organization roles.
 func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	_, _, err := d.client.ListUsers(ctx, trayclient.ListUsersParams{First: 1})
+	if err == nil {
+		l.Info("baton-trayai: authenticated with a master token")
+		return nil, nil
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		return nil, validationError(err)
+	}
+
+	if _, _, err := d.client.ListWorkspaces(ctx, trayclient.ListWorkspacesParams{First: 1}); err != nil {
+		return nil, validationError(err)
+	}
+	if d.resourceTypes.contains(userResourceType) || d.resourceTypes.contains(organizationResourceType) {
+		return nil, fmt.Errorf("baton-trayai: the auth-token is a user token, which cannot sync the organization users nor roles: "+
+			"use a master token, or --skip-resource-types %s,%s: %w", userResourceType.Id, organizationResourceType.Id, err)
+	}
+	l.Warn("baton-trayai: authenticated with a user token, organization users and roles cannot be synced nor provisioned")
 	return nil, nil
 }
 
+// validationError turns the error of the credentials probe into an actionable message.
+func validationError(err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case status.Code(err) == codes.Unauthenticated:
+		return fmt.Errorf("baton-trayai: the auth-token is invalid or revoked: %w", err)
+	case status.Code(err) == codes.PermissionDenied:
+		return fmt.Errorf("baton-trayai: the auth-token is not allowed to read users nor workspaces: %w", err)
+	case status.Code(err) == codes.NotFound:
+		return fmt.Errorf("baton-trayai: the tray.ai API was not found, check the region or base-url: %w", err)
+	case errors.As(err, &dnsErr):
+		return fmt.Errorf("baton-trayai: cannot resolve the tray.ai API host, check the region or base-url: %w", err)
+	case errors.As(err, &netErr):
+		return fmt.Errorf("baton-trayai: cannot reach the tray.ai API: %w", err)
+	default:
+		return fmt.Errorf("baton-trayai: cannot validate the auth-token: %w", err)
+	}
+}
+
+// unavailable reports whether a list call failed because the tenant doesn't have the API, like Embedded for
+// solutions, or the token isn't allowed to use it. Such resource types are synced as empty.
+func unavailable(ctx context.Context, err error, resourceType *v2.ResourceType) bool {
+	if code := status.Code(err); code != codes.PermissionDenied && code != codes.NotFound {
+		return false
+	}
+	ctxzap.Extract(ctx).Warn("baton-trayai: cannot list the resource type, skipping it",
+		zap.String("resource_type", resourceType.Id), zap.Error(err))
+	return true
+}
+
 // New returns a new instance of the connector.
-func New(ctx context.Context, apiKey string) (*Connector, error) {
-	httpClient, err := uhttp.NewBearerAuth(apiKey).GetClient(ctx)
+func New(ctx context.Context, cfg Config) (*Connector, error) {
+	if err := cfg.Filters.Validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Filters.ValidateSyncUsers(cfg.SyncUsers); err != nil {
+		return nil, err
+	}
+	resourceTypes, err := cfg.Filters.resourceTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := uhttp.NewBearerAuth(cfg.AuthToken).GetClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("baton-trayai: cannot init connector: %w", err)
 	}
@@ -53,6 +173,21 @@ func New(ctx context.Context, apiKey string) (*Connector, error) {
 	return &Connector{
 		client: trayclient.NewClient(trayclient.Params{
 			HttpClient: uhttp.NewBaseHttpClient(httpClient),
+			BaseURL:    cfg.BaseURL,
+			GraphQLURL: cfg.GraphQLURL,
+			PageSize:   cfg.PageSize,
 		}),
+		userOptions: userBuilderOptions{
+			excludeExternalUsers: cfg.ExcludeExternalUsers,
+			successorUserID:      cfg.SuccessorUserID,
+			syncUsage:            cfg.SyncUserUsage,
+			syncUsers:            cfg.SyncUsers,
+		},
+		deleteOrphanedAuthsOnly: cfg.DeleteOrphanedAuthenticationsOnly,
+		resourceTypes:           resourceTypes,
+		workspaceFilter: workspaceFilter{
+			include: cfg.Filters.IncludeWorkspaces,
+			exclude: cfg.Filters.ExcludeWorkspaces,
+		},
 	}, nil
 }
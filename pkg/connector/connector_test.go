@@ -0,0 +1,275 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newTestConnector returns a Connector pointed at a local server running handler, with the HTTP cache disabled.
+func newTestConnector(t *testing.T, handler http.HandlerFunc) *Connector {
+	t.Helper()
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "true")
+	return startTestConnector(t, handler)
+}
+
+// newCachingTestConnector is newTestConnector with the HTTP cache enabled, for the calls that must bypass it.
+func newCachingTestConnector(t *testing.T, handler http.HandlerFunc) *Connector {
+	t.Helper()
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "false")
+	return startTestConnector(t, handler)
+}
+
+func startTestConnector(t *testing.T, handler http.HandlerFunc) *Connector {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(context.Background(), Config{AuthToken: "token", BaseURL: server.URL})
+	require.NoError(t, err)
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name            string
+		usersStatus     int
+		workspaceStatus int
+		skip            []string
+		wantErr         string
+	}{
+		{name: "master token", usersStatus: http.StatusOK},
+		{name: "user token", usersStatus: http.StatusForbidden, workspaceStatus: http.StatusOK, wantErr: "use a master token, or --skip-resource-types user,organization"},
+		{name: "user token without org resources", usersStatus: http.StatusForbidden, workspaceStatus: http.StatusOK, skip: []string{"user", "organization"}},
+		{name: "invalid token", usersStatus: http.StatusUnauthorized, wantErr: "invalid or revoked"},
+		{name: "missing scope", usersStatus: http.StatusForbidden, workspaceStatus: http.StatusForbidden, wantErr: "not allowed"},
+		{name: "wrong region", usersStatus: http.StatusNotFound, wantErr: "check the region"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+				code := tc.usersStatus
+				if r.URL.Path == "/core/v1/workspaces" {
+					code = tc.workspaceStatus
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte(`{"elements": [], "pageInfo": {}}`))
+			})
+			resourceTypes, err := Filters{SkipResourceTypes: tc.skip}.resourceTypes()
+			require.NoError(t, err)
+			c.resourceTypes = resourceTypes
+
+			_, err = c.Validate(context.Background())
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	t.Run("unreachable host", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		ctx := context.Background()
+		c, err := New(ctx, Config{AuthToken: "token", BaseURL: server.URL})
+		require.NoError(t, err)
+
+		_, err = c.Validate(ctx)
+		require.ErrorContains(t, err, "cannot reach")
+	})
+}
+
+func TestListEvents(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/audit-logs", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		query := r.URL.Query()
+		// The next polls list from the latest entry read, which tray.ai returns again.
+		switch query.Get("from") {
+		case "2024-05-03T10:00:00Z":
+			require.Empty(t, query.Get("cursor"))
+			_, _ = w.Write([]byte(`{
+				"elements": [
+					{"id": "e4", "eventType": "user.login", "timestamp": "2024-05-03T10:00:00Z", "actorId": "u1"},
+					{"id": "e5", "eventType": "user.login", "timestamp": "2024-05-04T10:00:00Z", "actorId": "u2"}
+				],
+				"pageInfo": {"hasNextPage": false}
+			}`))
+			return
+		case "2024-05-04T10:00:00Z":
+			require.Empty(t, query.Get("cursor"))
+			_, _ = w.Write([]byte(`{
+				"elements": [{"id": "e5", "eventType": "user.login", "timestamp": "2024-05-04T10:00:00Z", "actorId": "u2"}],
+				"pageInfo": {"hasNextPage": false}
+			}`))
+			return
+		}
+		require.Equal(t, "2024-05-01T00:00:00Z", query.Get("from"))
+		if query.Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{
+				"elements": [
+					{"id": "e1", "eventType": "user.login", "timestamp": "2024-04-30T23:59:00Z", "actorId": "u1"},
+					{"id": "e2", "eventType": "workspace.user.added", "timestamp": "2024-05-01T10:00:00Z", "targetUserId": "u1", "workspaceId": "w1", "role": "viewer"}
+				],
+				"pageInfo": {"endCursor": "page2", "hasNextPage": true}
+			}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "e0", "eventType": "workspace.user.removed", "timestamp": "2024-04-01T00:00:00Z", "targetUserId": "u2", "workspaceId": "w1", "role": "admin"},
+				{"id": "e3", "eventType": "workspace.user.role_updated", "timestamp": "2024-05-02T10:00:00Z", "targetUserId": "u1", "workspaceId": "w1", "role": "admin", "previousRole": "viewer"},
+				{"id": "e4", "eventType": "user.login", "timestamp": "2024-05-03T10:00:00Z", "actorId": "u1"}
+			],
+			"pageInfo": {"hasNextPage": false}
+		}`))
+	})
+
+	ctx := context.Background()
+	earliest := timestamppb.New(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	events, state, _, err := c.ListEvents(ctx, earliest, &pagination.StreamToken{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "e2", events[0].Id)
+	require.Equal(t, "workspace:w1:viewer", events[0].GetGrantEvent().GetGrant().GetEntitlement().GetId())
+	require.True(t, state.HasMore)
+
+	events, state, _, err = c.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: state.Cursor})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, "workspace:w1:admin", events[0].GetGrantEvent().GetGrant().GetEntitlement().GetId())
+	require.Equal(t, "workspace:w1:viewer", events[1].GetRevokeEvent().GetEntitlement().GetId())
+	require.Equal(t, "u1", events[2].GetUsageEvent().GetActorResource().GetId().GetResource())
+	require.False(t, state.HasMore)
+
+	// Polling again emits only the entries added since.
+	events, state, _, err = c.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: state.Cursor})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "e5", events[0].Id)
+	require.False(t, state.HasMore)
+
+	events, _, _, err = c.ListEvents(ctx, earliest, &pagination.StreamToken{Cursor: state.Cursor})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestListEventsResumesFromPageCursor(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "page2", r.URL.Query().Get("cursor"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [{"id": "e3", "eventType": "user.login", "timestamp": "2024-05-02T10:00:00Z", "actorId": "u1"}],
+			"pageInfo": {"hasNextPage": false}
+		}`))
+	})
+
+	events, state, _, err := c.ListEvents(context.Background(), nil, &pagination.StreamToken{Cursor: "page2"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.False(t, state.HasMore)
+}
+
+func TestListEventsFiltersWorkspacesByName(t *testing.T) {
+	lookups := map[string]int{}
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/core/v1/audit-logs":
+			_, _ = w.Write([]byte(`{
+				"elements": [
+					{"id": "e1", "eventType": "workspace.user.added", "timestamp": "2024-05-01T10:00:00Z", "targetUserId": "u1", "workspaceId": "w1", "role": "viewer"},
+					{"id": "e2", "eventType": "workspace.user.added", "timestamp": "2024-05-01T11:00:00Z", "targetUserId": "u1", "workspaceId": "w2", "role": "viewer"},
+					{"id": "e3", "eventType": "workspace.user.added", "timestamp": "2024-05-01T12:00:00Z", "targetUserId": "u1", "workspaceId": "w3", "role": "viewer"},
+					{"id": "e4", "eventType": "workspace.user.removed", "timestamp": "2024-05-01T13:00:00Z", "targetUserId": "u2", "workspaceId": "w1", "role": "admin"},
+					{"id": "e5", "eventType": "workspace.user.added", "timestamp": "2024-05-01T14:00:00Z", "targetUserId": "u1", "workspaceId": "gone", "role": "viewer"}
+				],
+				"pageInfo": {}
+			}`))
+		case "/core/v1/workspaces/w1":
+			lookups["w1"]++
+			_, _ = w.Write([]byte(`{"id": "w1", "name": "Sales EMEA"}`))
+		case "/core/v1/workspaces/w2":
+			lookups["w2"]++
+			_, _ = w.Write([]byte(`{"id": "w2", "name": "Sales Sandbox"}`))
+		case "/core/v1/workspaces/w3":
+			lookups["w3"]++
+			_, _ = w.Write([]byte(`{"id": "w3", "name": "Marketing"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	c.workspaceFilter = workspaceFilter{
+		include: []string{"Sales *", "gone"},
+		exclude: []string{"* Sandbox"},
+	}
+
+	ctx := context.Background()
+	events, _, _, err := c.ListEvents(ctx, nil, &pagination.StreamToken{})
+	require.NoError(t, err)
+	var ids []string
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+	require.Equal(t, []string{"e1", "e4", "e5"}, ids)
+	require.Equal(t, map[string]int{"w1": 1, "w2": 1, "w3": 1}, lookups)
+}
+
+func TestListSkipsUnavailableResourceTypes(t *testing.T) {
+	for _, code := range []int{http.StatusForbidden, http.StatusNotFound} {
+		t.Run(http.StatusText(code), func(t *testing.T) {
+			c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(code)
+				_, _ = w.Write([]byte(`{"message": "not available"}`))
+			})
+
+			ctx := context.Background()
+			solutionID := &v2.ResourceId{ResourceType: solutionResourceType.Id, Resource: "s1"}
+			serviceAccountID := &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa1"}
+			lists := map[string]func() ([]*v2.Resource, string, annotations.Annotations, error){
+				"solution": func() ([]*v2.Resource, string, annotations.Annotations, error) {
+					return newSolutionBuilder(c.client, nil).List(ctx, nil, &pagination.Token{})
+				},
+				"solution_instance": func() ([]*v2.Resource, string, annotations.Annotations, error) {
+					return newSolutionInstanceBuilder(c.client).List(ctx, solutionID, &pagination.Token{})
+				},
+				"service_account": func() ([]*v2.Resource, string, annotations.Annotations, error) {
+					return newServiceAccountBuilder(c.client, nil).List(ctx, nil, &pagination.Token{})
+				},
+				"api_token": func() ([]*v2.Resource, string, annotations.Annotations, error) {
+					return newAPITokenBuilder(c.client).List(ctx, serviceAccountID, &pagination.Token{})
+				},
+			}
+			for name, list := range lists {
+				resources, next, _, err := list()
+				require.NoError(t, err, name)
+				require.Empty(t, resources, name)
+				require.Empty(t, next, name)
+			}
+		})
+	}
+
+	t.Run("other errors fail the sync", func(t *testing.T) {
+		c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		_, _, _, err := newServiceAccountBuilder(c.client, nil).List(context.Background(), nil, &pagination.Token{})
+		require.Error(t, err)
+	})
+}
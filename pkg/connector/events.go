@@ -0,0 +1,253 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	trayclient "github.com/conductorone/baton-trayai/pkg/connector/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ListEvents returns the tray.ai audit log entries that occurred at or after earliestEvent as Baton events. Logins
+// become usage events and workspace membership changes become grant and revoke events of the workspace roles.
+// Entries of other types are skipped.
+func (d *Connector) ListEvents(
+	ctx context.Context,
+	earliestEvent *timestamppb.Timestamp,
+	pToken *pagination.StreamToken,
+) ([]*v2.Event, *pagination.StreamState, annotations.Annotations, error) {
+	var events []*v2.Event
+
+	cursor := parseEventCursor(pToken.Cursor)
+	from := cursor.From
+	if earliestEvent != nil && earliestEvent.AsTime().After(from) {
+		from = earliestEvent.AsTime()
+	}
+
+	resp, annos, err := d.client.ListAuditLogs(ctx, trayclient.ListAuditLogsParams{
+		From:   from,
+		Cursor: cursor.Page,
+		First:  pToken.Size,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("baton-trayai: ListAuditLogs failed: %w", err)
+	}
+
+	// The workspaces of the page, looked up once each to filter the entries by name.
+	workspaces := make(map[string]trayclient.Workspace)
+	for _, entry := range resp.Entries {
+		// The cursor of a resumed stream may point before earliestEvent, so every page is filtered.
+		if entry.OccurredAt.Before(from) || cursor.read(entry) {
+			continue
+		}
+		cursor.advance(entry)
+
+		synced, err := d.syncsAuditLogEntry(ctx, entry, workspaces)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !synced {
+			continue
+		}
+		events = append(events, auditLogEvents(entry)...)
+	}
+
+	cursor.Page = resp.Page.NextCursor()
+	if cursor.Page == "" {
+		// The last page was read: the next poll lists the entries from the latest one read on.
+		cursor.From = cursor.Latest
+	} else {
+		// The pages of a listing are fetched with the same query.
+		cursor.From = from
+	}
+	next, err := cursor.String()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return events, &pagination.StreamState{
+		Cursor:  next,
+		HasMore: cursor.Page != "",
+	}, annos, nil
+}
+
+// eventCursor is the stream cursor of ListEvents. The tray.ai page cursor alone can't resume the stream: the
+// last page has none, and listing from the start again would emit its entries twice.
+type eventCursor struct {
+	// From is the time the audit log is listed from.
+	From time.Time `json:"from"`
+	// Page is the tray.ai cursor of the next page of the listing, empty once its last page was read.
+	Page string `json:"page,omitempty"`
+	// Latest is the time of the latest entry read, and LatestIDs the IDs of the entries read at that time. The
+	// entries are listed oldest first, so those not after them were already read.
+	Latest    time.Time `json:"latest"`
+	LatestIDs []string  `json:"latest_ids,omitempty"`
+}
+
+// parseEventCursor decodes the stream cursor. Streams started before the cursor held more than the tray.ai page
+// cursor resume from that page.
+func parseEventCursor(raw string) eventCursor {
+	var cursor eventCursor
+	if raw == "" {
+		return cursor
+	}
+	if err := json.Unmarshal([]byte(raw), &cursor); err != nil {
+		return eventCursor{Page: raw}
+	}
+	return cursor
+}
+
+// read returns whether the entry was read by a previous call.
+func (c *eventCursor) read(entry trayclient.AuditLogEntry) bool {
+	if entry.OccurredAt.Before(c.Latest) {
+		return true
+	}
+	return entry.OccurredAt.Equal(c.Latest) && slices.Contains(c.LatestIDs, entry.ID)
+}
+
+// advance records the entry as read.
+func (c *eventCursor) advance(entry trayclient.AuditLogEntry) {
+	if !entry.OccurredAt.Equal(c.Latest) {
+		c.Latest = entry.OccurredAt
+		c.LatestIDs = nil
+	}
+	c.LatestIDs = append(c.LatestIDs, entry.ID)
+}
+
+func (c eventCursor) String() (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("baton-trayai: cannot encode the event cursor: %w", err)
+	}
+	return string(raw), nil
+}
+
+// syncsAuditLogEntry returns whether the entry concerns resources the connector syncs. The entries only carry
+// the ID of the workspace, so its name is looked up for the name globs of the workspace filter and kept in
+// workspaces for the following entries.
+func (d *Connector) syncsAuditLogEntry(
+	ctx context.Context,
+	entry trayclient.AuditLogEntry,
+	workspaces map[string]trayclient.Workspace,
+) (bool, error) {
+	if entry.EventType == trayclient.AuditEventUserLogin {
+		return d.resourceTypes.contains(userResourceType), nil
+	}
+	if !d.resourceTypes.contains(workspaceResourceType) {
+		return false, nil
+	}
+	if d.workspaceFilter.empty() {
+		return true, nil
+	}
+
+	workspace, ok := workspaces[entry.WorkspaceID]
+	if !ok {
+		found, err := d.client.GetWorkspace(ctx, entry.WorkspaceID)
+		switch {
+		case err == nil:
+			workspace = *found
+		case status.Code(err) == codes.NotFound:
+			// The workspace was removed since, only its ID can be matched.
+			workspace = trayclient.Workspace{ID: entry.WorkspaceID}
+		default:
+			return false, fmt.Errorf("baton-trayai: GetWorkspace failed: %w", err)
+		}
+		workspaces[entry.WorkspaceID] = workspace
+	}
+	return d.workspaceFilter.matches(workspace), nil
+}
+
+// auditLogEvents maps an audit log entry onto the Baton events it stands for.
+func auditLogEvents(entry trayclient.AuditLogEntry) []*v2.Event {
+	occurredAt := timestamppb.New(entry.OccurredAt)
+	user := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: userResourceType.Id,
+			Resource:     entry.TargetUserID,
+		},
+	}
+	workspace := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: workspaceResourceType.Id,
+			Resource:     entry.WorkspaceID,
+		},
+	}
+
+	isMembershipChange := entry.WorkspaceID != "" && entry.TargetUserID != "" && entry.Role != ""
+	if entry.EventType != trayclient.AuditEventUserLogin && !isMembershipChange {
+		return nil
+	}
+
+	grantEvent := func(id, role string) *v2.Event {
+		return &v2.Event{
+			Id:         id,
+			OccurredAt: occurredAt,
+			Event: &v2.Event_GrantEvent{
+				GrantEvent: &v2.GrantEvent{
+					Grant: grant.NewGrant(workspace, role, user.Id),
+				},
+			},
+		}
+	}
+	revokeEvent := func(id, role string) *v2.Event {
+		return &v2.Event{
+			Id:         id,
+			OccurredAt: occurredAt,
+			Event: &v2.Event_RevokeEvent{
+				RevokeEvent: &v2.RevokeEvent{
+					Entitlement: entitlement.NewPermissionEntitlement(workspace, role),
+					Principal:   user,
+				},
+			},
+		}
+	}
+
+	switch entry.EventType {
+	case trayclient.AuditEventUserLogin:
+		// The user logging in is the actor, older entries only carry it as the target.
+		if entry.ActorID != "" {
+			user.Id.Resource = entry.ActorID
+		}
+		return []*v2.Event{
+			{
+				Id:         entry.ID,
+				OccurredAt: occurredAt,
+				Event: &v2.Event_UsageEvent{
+					UsageEvent: &v2.UsageEvent{
+						TargetResource: &v2.Resource{
+							Id: &v2.ResourceId{
+								ResourceType: organizationResourceType.Id,
+								Resource:     organizationID,
+							},
+						},
+						ActorResource: user,
+					},
+				},
+			},
+		}
+
+	case trayclient.AuditEventWorkspaceUserAdded:
+		return []*v2.Event{grantEvent(entry.ID, entry.Role)}
+
+	case trayclient.AuditEventWorkspaceUserRemoved:
+		return []*v2.Event{revokeEvent(entry.ID, entry.Role)}
+
+	case trayclient.AuditEventWorkspaceUserRoleUpdated:
+		events := []*v2.Event{grantEvent(entry.ID, entry.Role)}
+		if entry.PreviousRole != "" && entry.PreviousRole != entry.Role {
+			events = append(events, revokeEvent(entry.ID+":revoke", entry.PreviousRole))
+		}
+		return events
+	}
+
+	return nil
+}
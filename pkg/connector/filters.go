@@ -0,0 +1,183 @@
+package connector
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+)
+
+// allResourceTypes are the resource types the connector can sync.
+var allResourceTypes = []*v2.ResourceType{
+	userResourceType,
+	workspaceResourceType,
+	organizationResourceType,
+	projectResourceType,
+	workflowResourceType,
+	solutionResourceType,
+	solutionInstanceResourceType,
+	authenticationResourceType,
+	serviceAccountResourceType,
+	apiTokenResourceType,
+}
+
+// parentResourceTypes maps the resource types that are only listed under a parent to that parent's type.
+var parentResourceTypes = map[string]*v2.ResourceType{
+	projectResourceType.Id:          workspaceResourceType,
+	authenticationResourceType.Id:   workspaceResourceType,
+	workflowResourceType.Id:         workspaceResourceType,
+	solutionInstanceResourceType.Id: solutionResourceType,
+	apiTokenResourceType.Id:         serviceAccountResourceType,
+}
+
+// Filters scopes the sync to some resource types and workspaces.
+type Filters struct {
+	// ResourceTypes are the IDs of the resource types to sync. All of them are synced when empty.
+	ResourceTypes []string
+	// SkipResourceTypes are the IDs of the resource types not to sync.
+	SkipResourceTypes []string
+	// IncludeWorkspaces limits the sync to the workspaces matching one of these IDs or name globs.
+	IncludeWorkspaces []string
+	// ExcludeWorkspaces skips the workspaces matching one of these IDs or name globs, along with their projects,
+	// workflows and authentications.
+	ExcludeWorkspaces []string
+}
+
+// Validate returns an error if the filters name unknown resource types, skip the parent of a synced resource type
+// or hold a malformed glob.
+func (f Filters) Validate() error {
+	if _, err := f.resourceTypes(); err != nil {
+		return err
+	}
+	for _, pattern := range slices.Concat(f.IncludeWorkspaces, f.ExcludeWorkspaces) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("baton-trayai: invalid workspace pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// ValidateSyncUsers returns an error if users are named to be synced alone while other resource types are synced
+// too. The grants of those would point at every user, but only the named users would be in the sync, so the
+// others would look deleted.
+func (f Filters) ValidateSyncUsers(syncUsers []string) error {
+	if len(syncUsers) == 0 {
+		return nil
+	}
+	set, err := f.resourceTypes()
+	if err != nil {
+		return err
+	}
+	if len(set) != 1 || !set.contains(userResourceType) {
+		return fmt.Errorf("baton-trayai: sync-users requires resource-types to be limited to %s", userResourceType.Id)
+	}
+	return nil
+}
+
+// resourceTypes returns the set of the resource types to sync.
+func (f Filters) resourceTypes() (resourceTypeSet, error) {
+	known := make(map[string]bool, len(allResourceTypes))
+	for _, rt := range allResourceTypes {
+		known[rt.Id] = true
+	}
+	for _, id := range slices.Concat(f.ResourceTypes, f.SkipResourceTypes) {
+		if !known[id] {
+			return nil, fmt.Errorf("baton-trayai: unknown resource type %q, expected one of: %s", id, strings.Join(resourceTypeIDs(), ", "))
+		}
+	}
+
+	set := resourceTypeSet{}
+	for _, rt := range allResourceTypes {
+		if (len(f.ResourceTypes) == 0 || slices.Contains(f.ResourceTypes, rt.Id)) && !slices.Contains(f.SkipResourceTypes, rt.Id) {
+			set[rt.Id] = true
+		}
+	}
+
+	// Child resources are only listed under their parent, so they can't be synced without it.
+	for id := range set {
+		if parent, ok := parentResourceTypes[id]; ok && !set[parent.Id] {
+			return nil, fmt.Errorf("baton-trayai: resource type %s can't be synced without its parent resource type %s", id, parent.Id)
+		}
+	}
+	return set, nil
+}
+
+// resourceTypeIDs returns the IDs of all the resource types the connector can sync.
+func resourceTypeIDs() []string {
+	ids := make([]string, 0, len(allResourceTypes))
+	for _, rt := range allResourceTypes {
+		ids = append(ids, rt.Id)
+	}
+	return ids
+}
+
+// resourceTypeSet is the set of the IDs of the resource types to sync.
+type resourceTypeSet map[string]bool
+
+func (s resourceTypeSet) contains(rt *v2.ResourceType) bool {
+	return s[rt.Id]
+}
+
+// children returns the child resource types that are synced among types.
+func (s resourceTypeSet) children(types ...*v2.ResourceType) []*v2.ResourceType {
+	var children []*v2.ResourceType
+	for _, rt := range types {
+		if s.contains(rt) {
+			children = append(children, rt)
+		}
+	}
+	return children
+}
+
+// withChildResourceTypes annotates a resource with its child resource types, so the SDK lists them under it.
+func withChildResourceTypes(types []*v2.ResourceType) resource.ResourceOption {
+	annos := make([]*v2.ChildResourceType, 0, len(types))
+	for _, rt := range types {
+		annos = append(annos, &v2.ChildResourceType{ResourceTypeId: rt.Id})
+	}
+	return func(r *v2.Resource) error {
+		for _, anno := range annos {
+			if err := resource.WithAnnotation(anno)(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// workspaceFilter selects the workspaces to sync from include and exclude lists of IDs or name globs.
+type workspaceFilter struct {
+	include []string
+	exclude []string
+}
+
+// empty returns whether the filter selects every workspace.
+func (f workspaceFilter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+// matches returns whether the workspace is to be synced. Exclusions win over inclusions.
+func (f workspaceFilter) matches(workspace client.Workspace) bool {
+	if len(f.include) > 0 && !matchesWorkspace(f.include, workspace) {
+		return false
+	}
+	return !matchesWorkspace(f.exclude, workspace)
+}
+
+// matchesWorkspace returns whether one of the patterns is the workspace ID or a glob matching its name.
+func matchesWorkspace(patterns []string, workspace client.Workspace) bool {
+	for _, pattern := range patterns {
+		if pattern == workspace.ID {
+			return true
+		}
+		// The patterns are checked by Filters.Validate, so a failed match is just a mismatch.
+		if ok, _ := path.Match(pattern, workspace.Name); ok {
+			return true
+		}
+	}
+	return false
+}
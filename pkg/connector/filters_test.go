@@ -0,0 +1,58 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceSyncersFilters(t *testing.T) {
+	ctx := context.Background()
+	syncedTypes := func(filters Filters) []string {
+		c, err := New(ctx, Config{AuthToken: "token", Filters: filters})
+		require.NoError(t, err)
+
+		var ids []string
+		for _, syncer := range c.ResourceSyncers(ctx) {
+			ids = append(ids, syncer.ResourceType(ctx).Id)
+		}
+		return ids
+	}
+
+	require.Equal(t, resourceTypeIDs(), syncedTypes(Filters{}))
+	require.Equal(t, []string{"user", "organization"}, syncedTypes(Filters{ResourceTypes: []string{"organization", "user"}}))
+	require.NotContains(t, syncedTypes(Filters{SkipResourceTypes: []string{"api_token"}}), "api_token")
+	require.Equal(t, []string{"workspace", "workflow"}, syncedTypes(Filters{ResourceTypes: []string{"workspace", "workflow"}}))
+
+	_, err := New(ctx, Config{AuthToken: "token", Filters: Filters{ResourceTypes: []string{"project"}}})
+	require.ErrorContains(t, err, "without its parent resource type workspace")
+}
+
+func TestValidateSyncUsers(t *testing.T) {
+	require.NoError(t, Filters{}.ValidateSyncUsers(nil))
+	require.NoError(t, Filters{ResourceTypes: []string{"user"}}.ValidateSyncUsers([]string{"ada@example.com"}))
+
+	// The grants of the other resource types would point at the users left out of the sync.
+	require.ErrorContains(t, Filters{}.ValidateSyncUsers([]string{"u1"}), "limited to user")
+	require.ErrorContains(t, Filters{ResourceTypes: []string{"user", "organization"}}.ValidateSyncUsers([]string{"u1"}), "limited to user")
+
+	_, err := New(context.Background(), Config{AuthToken: "token", SyncUsers: []string{"u1"}})
+	require.ErrorContains(t, err, "limited to user")
+}
+
+func TestWorkspaceFilter(t *testing.T) {
+	prod := client.Workspace{ID: "ws-1", Name: "prod-billing"}
+	sandbox := client.Workspace{ID: "ws-2", Name: "sandbox-ada"}
+
+	require.True(t, workspaceFilter{}.matches(prod))
+
+	filter := workspaceFilter{include: []string{"prod-*", "ws-2"}}
+	require.True(t, filter.matches(prod))
+	require.True(t, filter.matches(sandbox))
+
+	filter = workspaceFilter{include: []string{"*"}, exclude: []string{"sandbox-*"}}
+	require.True(t, filter.matches(prod))
+	require.False(t, filter.matches(sandbox))
+}
@@ -0,0 +1,133 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The tray.ai API is scoped to a single organization, so it is synced as one resource with a fixed ID.
+const organizationID = "organization"
+
+// orgRoles are the organization-wide roles a user can hold. Each one is exposed as an entitlement.
+var orgRoles = []string{
+	client.OrgRoleOwner,
+	client.OrgRoleAdmin,
+	client.OrgRoleMember,
+}
+
+// privilegedOrgRoles are the org roles with administrative rights over the whole organization.
+var privilegedOrgRoles = []string{
+	client.OrgRoleOwner,
+	client.OrgRoleAdmin,
+}
+
+type organizationBuilder struct {
+	client *client.Client
+}
+
+func (o *organizationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return organizationResourceType
+}
+
+// List returns the tray.ai organization as a single resource object.
+func (o *organizationBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	org, err := resource.NewResource(
+		"Tray.ai Organization",
+		organizationResourceType,
+		organizationID,
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+	}
+	return []*v2.Resource{org}, "", nil, nil
+}
+
+// Entitlements returns one entitlement per org role. The administrative ones say so in their display name and
+// description so reviewers can triage them first.
+func (o *organizationBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	entitlements := make([]*v2.Entitlement, 0, len(orgRoles))
+	for _, role := range orgRoles {
+		displayName := fmt.Sprintf("Organization %s", role)
+		description := fmt.Sprintf("Has the %s role in the tray.ai organization", role)
+		if slices.Contains(privilegedOrgRoles, role) {
+			displayName += " (administrative)"
+			description += ", with administrative rights over the whole organization"
+		}
+		entitlements = append(entitlements, entitlement.NewPermissionEntitlement(
+			resource,
+			role,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(displayName),
+			entitlement.WithDescription(description),
+		))
+	}
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant of the matching org role entitlement for every organization member.
+func (o *organizationBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	var (
+		grants []*v2.Grant
+	)
+
+	resp, annos, err := o.client.ListUsers(ctx, client.ListUsersParams{
+		Cursor: pToken.Token,
+		First:  pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListUsers failed: %w", err)
+	}
+
+	for _, listed := range resp.Users {
+		// External users don't belong to the organization and hold no org role.
+		if listed.IsExternal() {
+			continue
+		}
+
+		// The list endpoint is too sparse to be relied on for the role, so read it from the full user like the
+		// user sync does, which has usually cached it already.
+		user, err := o.client.GetUser(ctx, listed.ID)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				// The user was removed since the list call.
+				continue
+			}
+			return nil, "", nil, fmt.Errorf("baton-trayai: GetUser failed: %w", err)
+		}
+		if user == nil {
+			return nil, "", nil, status.Errorf(codes.Internal, "baton-trayai: tray.ai returned no body for user %s", listed.ID)
+		}
+		if user.IsExternal() || !slices.Contains(orgRoles, user.OrgRole) {
+			continue
+		}
+
+		grants = append(grants, grant.NewGrant(
+			resource,
+			user.OrgRole,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     user.ID,
+			},
+		))
+	}
+
+	return grants, resp.Page.NextCursor(), annos, nil
+}
+
+func newOrganizationBuilder(c *client.Client) *organizationBuilder {
+	return &organizationBuilder{
+		client: c,
+	}
+}
@@ -0,0 +1,62 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationGrants(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/core/v1/users":
+			// The list endpoint omits the org role.
+			_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}, {"id": "u2"}, {"id": "u3", "type": "external"}, {"id": "gone"}], "pageInfo": {}}`))
+		case "/core/v1/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "name": "Ada", "organizationRole": "admin"}`))
+		case "/core/v1/users/u2":
+			_, _ = w.Write([]byte(`{"id": "u2", "name": "Grace", "organizationRole": "member"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	builder := newOrganizationBuilder(c.client)
+	orgs, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+
+	grants, _, _, err := builder.Grants(ctx, orgs[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+	require.Equal(t, "organization:organization:admin", grants[0].Entitlement.Id)
+	require.Equal(t, "u2", grants[1].Principal.Id.Resource)
+	require.Equal(t, "organization:organization:member", grants[1].Entitlement.Id)
+}
+
+func TestOrganizationEntitlements(t *testing.T) {
+	ctx := context.Background()
+	builder := newOrganizationBuilder(nil)
+	orgs, _, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+
+	entitlements, _, _, err := builder.Entitlements(ctx, orgs[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, entitlements, 3)
+
+	// The administrative roles are marked in their display name and description.
+	require.Equal(t, "Organization owner (administrative)", entitlements[0].DisplayName)
+	require.Equal(t, "Organization admin (administrative)", entitlements[1].DisplayName)
+	require.Contains(t, entitlements[1].Description, "administrative rights")
+	require.Equal(t, "Organization member", entitlements[2].DisplayName)
+	require.NotContains(t, entitlements[2].Description, "administrative")
+	for _, ent := range entitlements {
+		require.Empty(t, ent.Annotations)
+	}
+}
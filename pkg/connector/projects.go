@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// projectRoles are the roles a user can hold in a project. Each one is exposed as an entitlement.
+var projectRoles = []string{
+	client.ProjectRoleOwner,
+	client.ProjectRoleEditor,
+	client.ProjectRoleViewer,
+}
+
+// Create a new connector resource for a tray.ai project. childTypes are the synced resource types nested under
+// projects.
+func projectResource(
+	_ context.Context,
+	project client.Project,
+	childTypes []*v2.ResourceType,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	return resource.NewResource(
+		project.Name,
+		projectResourceType,
+		project.ID,
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(project.Description),
+		withChildResourceTypes(childTypes),
+	)
+}
+
+type projectBuilder struct {
+	client     *client.Client
+	childTypes []*v2.ResourceType
+}
+
+func (o *projectBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return projectResourceType
+}
+
+// List returns the projects of the parent workspace as resource objects.
+func (o *projectBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != workspaceResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	var (
+		projects []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListProjects(ctx, client.ListProjectsParams{
+		WorkspaceID: parentResourceID.Resource,
+		Cursor:      pToken.Token,
+		First:       pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListProjects failed: %w", err)
+	}
+
+	for _, project := range resp.Projects {
+		vProject, err := projectResource(ctx, project, o.childTypes, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		projects = append(projects, vProject)
+	}
+
+	return projects, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns one entitlement per project role.
+func (o *projectBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	entitlements := make([]*v2.Entitlement, 0, len(projectRoles))
+	for _, role := range projectRoles {
+		entitlements = append(entitlements, entitlement.NewPermissionEntitlement(
+			resource,
+			role,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Project %s", resource.DisplayName, role)),
+			entitlement.WithDescription(fmt.Sprintf("Has the %s role in the %s project", role, resource.DisplayName)),
+		))
+	}
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant of the matching role entitlement for every collaborator of the project.
+func (o *projectBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var (
+		grants []*v2.Grant
+	)
+
+	resp, annos, err := o.client.ListProjectCollaborators(ctx, client.ListProjectCollaboratorsParams{
+		ProjectID: resource.Id.Resource,
+		Cursor:    pToken.Token,
+		First:     pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListProjectCollaborators failed: %w", err)
+	}
+
+	for _, collaborator := range resp.Collaborators {
+		if !slices.Contains(projectRoles, collaborator.Role) {
+			l.Debug("baton-trayai: skipping project collaborator with unknown role",
+				zap.String("project_id", resource.Id.Resource),
+				zap.String("user_id", collaborator.ID),
+				zap.String("role", collaborator.Role),
+			)
+			continue
+		}
+		grants = append(grants, grant.NewGrant(
+			resource,
+			collaborator.Role,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     collaborator.ID,
+			},
+		))
+	}
+
+	return grants, resp.Page.NextCursor(), annos, nil
+}
+
+func newProjectBuilder(c *client.Client, childTypes []*v2.ResourceType) *projectBuilder {
+	return &projectBuilder{
+		client:     c,
+		childTypes: childTypes,
+	}
+}
@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProjects(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/projects", r.URL.Path)
+		require.Equal(t, "w1", r.URL.Query().Get("workspaceId"))
+		require.Equal(t, "2", r.URL.Query().Get("first"))
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{
+				"elements": [
+					{"id": "p1", "name": "Leads", "description": "Lead routing"},
+					{"id": "p2", "name": "Billing"}
+				],
+				"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+			}`))
+			return
+		}
+		require.Equal(t, "c1", r.URL.Query().Get("cursor"))
+		_, _ = w.Write([]byte(`{"elements": [{"id": "p3", "name": "Support"}], "pageInfo": {"hasNextPage": false}}`))
+	})
+
+	ctx := context.Background()
+	builder := newProjectBuilder(c.client, []*v2.ResourceType{workflowResourceType})
+
+	// Projects are only listed under their workspace.
+	projects, next, _, err := builder.List(ctx, nil, &pagination.Token{Size: 2})
+	require.NoError(t, err)
+	require.Empty(t, projects)
+	require.Empty(t, next)
+	orgID := &v2.ResourceId{ResourceType: organizationResourceType.Id, Resource: organizationID}
+	projects, _, _, err = builder.List(ctx, orgID, &pagination.Token{Size: 2})
+	require.NoError(t, err)
+	require.Empty(t, projects)
+
+	workspaceID := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "w1"}
+	projects, next, _, err = builder.List(ctx, workspaceID, &pagination.Token{Size: 2})
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	require.Equal(t, "c1", next)
+	require.Equal(t, "p1", projects[0].Id.Resource)
+	require.Equal(t, "Leads", projects[0].DisplayName)
+	require.Equal(t, "Lead routing", projects[0].Description)
+	require.Equal(t, "w1", projects[0].ParentResourceId.Resource)
+
+	projects, next, _, err = builder.List(ctx, workspaceID, &pagination.Token{Size: 2, Token: next})
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	require.Equal(t, "p3", projects[0].Id.Resource)
+	require.Empty(t, next)
+}
+
+func TestProjectGrants(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/projects/p1/collaborators", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "u1", "name": "Ada", "role": "owner"},
+				{"id": "u2", "name": "Grace", "role": "editor"},
+				{"id": "u3", "name": "Linus", "role": "auditor"},
+				{"id": "u4", "name": "Barbara", "role": "viewer"}
+			],
+			"pageInfo": {}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newProjectBuilder(c.client, nil)
+	project := &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: projectResourceType.Id, Resource: "p1"},
+		DisplayName: "Leads",
+	}
+
+	entitlements, _, _, err := builder.Entitlements(ctx, project, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, entitlements, 3)
+	require.Equal(t, "Leads Project owner", entitlements[0].DisplayName)
+
+	grants, _, _, err := builder.Grants(ctx, project, &pagination.Token{})
+	require.NoError(t, err)
+	// The collaborator with a role the connector doesn't know is skipped.
+	require.Len(t, grants, 3)
+	require.Equal(t, "project:p1:owner", grants[0].Entitlement.Id)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+	require.Equal(t, userResourceType.Id, grants[0].Principal.Id.ResourceType)
+	require.Equal(t, "project:p1:editor", grants[1].Entitlement.Id)
+	require.Equal(t, "u2", grants[1].Principal.Id.Resource)
+	require.Equal(t, "project:p1:viewer", grants[2].Entitlement.Id)
+	require.Equal(t, "u4", grants[2].Principal.Id.Resource)
+}
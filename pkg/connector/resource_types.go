@@ -10,3 +10,65 @@ var userResourceType = &v2.ResourceType{
 	DisplayName: "User",
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
 }
+
+// The workspace resource type is for all tray.ai workspaces, the main unit of access in tray.ai.
+var workspaceResourceType = &v2.ResourceType{
+	Id:          "workspace",
+	DisplayName: "Workspace",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+// The organization resource type is for the tray.ai organization itself, which carries the org-wide roles.
+var organizationResourceType = &v2.ResourceType{
+	Id:          "organization",
+	DisplayName: "Organization",
+}
+
+// The project resource type is for the tray.ai projects, which are nested under their workspace.
+var projectResourceType = &v2.ResourceType{
+	Id:          "project",
+	DisplayName: "Project",
+}
+
+// The workflow resource type is for the tray.ai workflows, nested under their project or, when they are in none,
+// under their workspace.
+var workflowResourceType = &v2.ResourceType{
+	Id:          "workflow",
+	DisplayName: "Workflow",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+// The solution resource type is for the tray.ai Embedded solutions.
+var solutionResourceType = &v2.ResourceType{
+	Id:          "solution",
+	DisplayName: "Solution",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+// The solution instance resource type is for the deployed instances of a solution, nested under their solution.
+var solutionInstanceResourceType = &v2.ResourceType{
+	Id:          "solution_instance",
+	DisplayName: "Solution Instance",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+// The authentication resource type is for the third-party credentials stored in tray.ai, nested under their workspace.
+var authenticationResourceType = &v2.ResourceType{
+	Id:          "authentication",
+	DisplayName: "Authentication",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
+
+// The service account resource type is for the tray.ai service accounts, the non-human identities calling the API.
+var serviceAccountResourceType = &v2.ResourceType{
+	Id:          "service_account",
+	DisplayName: "Service Account",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+// The API token resource type is for the tokens issued to a service account, nested under their service account.
+var apiTokenResourceType = &v2.ResourceType{
+	Id:          "api_token",
+	DisplayName: "API Token",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+}
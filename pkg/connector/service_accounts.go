@@ -0,0 +1,253 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Create a new connector resource for a tray.ai service account.
+func serviceAccountResource(
+	_ context.Context,
+	sa client.ServiceAccount,
+	childTypes []*v2.ResourceType,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":          sa.ID,
+		"name":        sa.Name,
+		"description": sa.Description,
+		"owner_id":    sa.OwnerID,
+	}
+
+	saStatus := v2.UserTrait_Status_STATUS_ENABLED
+	if sa.Disabled {
+		saStatus = v2.UserTrait_Status_STATUS_DISABLED
+	}
+
+	traitOptions := []resource.UserTraitOption{
+		resource.WithStatus(saStatus),
+		resource.WithUserProfile(profile),
+		resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_SERVICE),
+	}
+	if !sa.CreatedAt.IsZero() {
+		traitOptions = append(traitOptions, resource.WithCreatedAt(sa.CreatedAt))
+	}
+
+	return resource.NewUserResource(
+		sa.Name,
+		serviceAccountResourceType,
+		sa.ID,
+		traitOptions,
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(sa.Description),
+		withChildResourceTypes(childTypes),
+	)
+}
+
+type serviceAccountBuilder struct {
+	client     *client.Client
+	childTypes []*v2.ResourceType
+}
+
+func (o *serviceAccountBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return serviceAccountResourceType
+}
+
+// List returns all the service accounts of the organization as resource objects.
+func (o *serviceAccountBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		serviceAccounts []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListServiceAccounts(ctx, client.ListServiceAccountsParams{
+		Cursor: pToken.Token,
+		First:  pToken.Size,
+	})
+	if unavailable(ctx, err, serviceAccountResourceType) {
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListServiceAccounts failed: %w", err)
+	}
+
+	for _, sa := range resp.ServiceAccounts {
+		vServiceAccount, err := serviceAccountResource(ctx, sa, o.childTypes, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		serviceAccounts = append(serviceAccounts, vServiceAccount)
+	}
+
+	return serviceAccounts, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns the owner entitlement of the service account.
+func (o *serviceAccountBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			ownerEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Service Account owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Owns the %s service account", resource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant of the service account to the user who owns it.
+func (o *serviceAccountBuilder) Grants(ctx context.Context, res *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	userTrait, err := resource.GetUserTrait(res)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: cannot get user trait: %w", err)
+	}
+
+	ownerID, ok := resource.GetProfileStringValue(userTrait.GetProfile(), "owner_id")
+	if !ok || ownerID == "" {
+		return nil, "", nil, nil
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(
+			res,
+			ownerEntitlement,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     ownerID,
+			},
+		),
+	}, "", nil, nil
+}
+
+// rotationTokenName prefixes the name of the tokens issued by Rotate, telling them apart from the tokens
+// created for other purposes, such as CI.
+const rotationTokenName = "baton-trayai rotation"
+
+// Rotate issues a new API token to the service account, then revokes its previous token as found by
+// previousToken. No token is issued when the previous one can't be told apart from the others. When revoking
+// fails the previous token is left in place and the new one is revoked.
+func (o *serviceAccountBuilder) Rotate(
+	ctx context.Context,
+	resourceId *v2.ResourceId,
+	credentialOptions *v2.CredentialOptions,
+) ([]*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if resourceId.ResourceType != serviceAccountResourceType.Id {
+		return nil, nil, fmt.Errorf("baton-trayai: cannot rotate credentials of non-service-account resource %s", resourceId.ResourceType)
+	}
+	if credentialOptions.GetRandomPassword() == nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "baton-trayai: service account tokens can only be rotated to a random token")
+	}
+	serviceAccountID := resourceId.Resource
+
+	previous, err := o.previousToken(ctx, serviceAccountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	created, err := o.client.CreateServiceAccountToken(ctx, serviceAccountID, fmt.Sprintf("%s %s", rotationTokenName, time.Now().UTC().Format(time.RFC3339)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("baton-trayai: CreateServiceAccountToken failed: %w", err)
+	}
+
+	if previous != nil {
+		err := o.client.RevokeServiceAccountToken(ctx, serviceAccountID, previous.ID)
+		if err != nil && status.Code(err) != codes.NotFound {
+			if rollbackErr := o.client.RevokeServiceAccountToken(ctx, serviceAccountID, created.ID); rollbackErr != nil {
+				l.Error("baton-trayai: cannot revoke the new token after a failed rotation",
+					zap.String("service_account_id", serviceAccountID),
+					zap.String("token_id", created.ID),
+					zap.Error(rollbackErr),
+				)
+			}
+			return nil, nil, fmt.Errorf("baton-trayai: RevokeServiceAccountToken failed: %w", err)
+		}
+	}
+
+	return []*v2.PlaintextData{
+		{
+			Name:        "token",
+			Description: fmt.Sprintf("tray.ai API token %s", created.ID),
+			Bytes:       []byte(created.Token),
+		},
+	}, nil, nil
+}
+
+// RotateCapabilityDetails returns the credential options supported when rotating tokens. tray.ai generates the
+// token itself, so it is always a random secret.
+func (o *serviceAccountBuilder) RotateCapabilityDetails(_ context.Context) (*v2.CredentialDetailsCredentialRotation, annotations.Annotations, error) {
+	return &v2.CredentialDetailsCredentialRotation{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_RANDOM_PASSWORD,
+	}, nil, nil
+}
+
+// previousToken returns the token a rotation replaces: the latest token issued by a previous rotation or, on
+// the first rotation, the only token of the service account. nil is returned when the service account has no
+// token yet. Tokens created for other purposes are never revoked, so a service account with several tokens and
+// none issued by a rotation fails with FailedPrecondition: rotating it would leave the old credential valid.
+// The tokens are read without the HTTP cache so that a token issued by a recent rotation is seen.
+func (o *serviceAccountBuilder) previousToken(ctx context.Context, serviceAccountID string) (*client.APIToken, error) {
+	var (
+		tokens []client.APIToken
+		latest *client.APIToken
+	)
+
+	cursor := ""
+	for {
+		resp, _, err := o.client.ListServiceAccountTokens(ctx, client.ListServiceAccountTokensParams{
+			ServiceAccountID: serviceAccountID,
+			Cursor:           cursor,
+		}, client.WithoutCache())
+		if err != nil {
+			return nil, fmt.Errorf("baton-trayai: ListServiceAccountTokens failed: %w", err)
+		}
+		tokens = append(tokens, resp.Tokens...)
+
+		cursor = resp.Page.NextCursor()
+		if cursor == "" {
+			break
+		}
+	}
+
+	for _, token := range tokens {
+		if !strings.HasPrefix(token.Name, rotationTokenName) {
+			continue
+		}
+		if latest == nil || token.CreatedAt.After(latest.CreatedAt) {
+			latest = &token
+		}
+	}
+	if latest == nil && len(tokens) == 1 {
+		latest = &tokens[0]
+	}
+	if latest == nil && len(tokens) > 1 {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"baton-trayai: service account %s has %d tokens and none was issued by a rotation, revoke all but the one to rotate first",
+			serviceAccountID, len(tokens))
+	}
+	return latest, nil
+}
+
+func newServiceAccountBuilder(c *client.Client, childTypes []*v2.ResourceType) *serviceAccountBuilder {
+	return &serviceAccountBuilder{
+		client:     c,
+		childTypes: childTypes,
+	}
+}
@@ -0,0 +1,192 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListServiceAccounts(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/service-accounts", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "sa1", "name": "CI", "description": "Deploys workflows", "ownerId": "u1", "createdAt": "2024-05-01T10:00:00Z"},
+				{"id": "sa2", "name": "Legacy", "disabled": true}
+			],
+			"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newServiceAccountBuilder(c.client, []*v2.ResourceType{apiTokenResourceType})
+	accounts, next, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, "c1", next)
+
+	require.Equal(t, "CI", accounts[0].DisplayName)
+	require.Equal(t, "Deploys workflows", accounts[0].Description)
+	trait, err := resource.GetUserTrait(accounts[0])
+	require.NoError(t, err)
+	require.Equal(t, v2.UserTrait_ACCOUNT_TYPE_SERVICE, trait.GetAccountType())
+	require.Equal(t, v2.UserTrait_Status_STATUS_ENABLED, trait.GetStatus().GetStatus())
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), trait.GetCreatedAt().AsTime())
+
+	trait, err = resource.GetUserTrait(accounts[1])
+	require.NoError(t, err)
+	require.Equal(t, v2.UserTrait_ACCOUNT_TYPE_SERVICE, trait.GetAccountType())
+	require.Equal(t, v2.UserTrait_Status_STATUS_DISABLED, trait.GetStatus().GetStatus())
+
+	grants, _, _, err := builder.Grants(ctx, accounts[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "service_account:sa1:owner", grants[0].Entitlement.Id)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+	require.Equal(t, userResourceType.Id, grants[0].Principal.Id.ResourceType)
+
+	// Without an owner, the service account grants nothing.
+	grants, _, _, err = builder.Grants(ctx, accounts[1], &pagination.Token{})
+	require.NoError(t, err)
+	require.Empty(t, grants)
+}
+
+// fakeTokens serves the API token endpoints of service account sa1.
+type fakeTokens struct {
+	mu     sync.Mutex
+	tokens []client.APIToken
+	// failRevoke makes revoking this token fail.
+	failRevoke string
+	revoked    []string
+}
+
+func (f *fakeTokens) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"elements": f.tokens, "pageInfo": client.PageInfo{}})
+	case http.MethodPost:
+		var body struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		token := client.APIToken{
+			ID:        fmt.Sprintf("t%d", len(f.tokens)+len(f.revoked)+1),
+			Name:      body.Name,
+			CreatedAt: time.Date(2024, 6, 1, 0, 0, len(f.tokens)+len(f.revoked), 0, time.UTC),
+		}
+		f.tokens = append(f.tokens, token)
+		_ = json.NewEncoder(w).Encode(client.CreatedAPIToken{APIToken: token, Token: "secret-" + token.ID})
+	case http.MethodDelete:
+		id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if id == f.failRevoke {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		for i, token := range f.tokens {
+			if token.ID == id {
+				f.tokens = append(f.tokens[:i], f.tokens[i+1:]...)
+				f.revoked = append(f.revoked, id)
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newTestServiceAccountBuilder returns a builder serving the tokens of sa1 from fake. The HTTP cache is enabled,
+// since the previous token must not be looked up from it.
+func newTestServiceAccountBuilder(t *testing.T, fake *fakeTokens) *serviceAccountBuilder {
+	c := newCachingTestConnector(t, fake.ServeHTTP)
+	return newServiceAccountBuilder(c.client, nil)
+}
+
+func TestRotate(t *testing.T) {
+	ctx := context.Background()
+	serviceAccount := &v2.ResourceId{ResourceType: serviceAccountResourceType.Id, Resource: "sa1"}
+	randomToken := &v2.CredentialOptions{Options: &v2.CredentialOptions_RandomPassword_{
+		RandomPassword: &v2.CredentialOptions_RandomPassword{},
+	}}
+	ciToken := client.APIToken{ID: "ci", Name: "CI", CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	t.Run("replaces the token of the previous rotation", func(t *testing.T) {
+		fake := &fakeTokens{tokens: []client.APIToken{
+			ciToken,
+			{ID: "rot", Name: rotationTokenName + " 2024-04-01T00:00:00Z", CreatedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		}}
+		builder := newTestServiceAccountBuilder(t, fake)
+
+		secrets, _, err := builder.Rotate(ctx, serviceAccount, randomToken)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.Equal(t, []string{"rot"}, fake.revoked)
+
+		// The token issued by the first rotation is the one replaced by the second.
+		issued := strings.TrimPrefix(string(secrets[0].Bytes), "secret-")
+		_, _, err = builder.Rotate(ctx, serviceAccount, randomToken)
+		require.NoError(t, err)
+		require.Equal(t, []string{"rot", issued}, fake.revoked)
+		require.Equal(t, "ci", fake.tokens[0].ID)
+	})
+
+	t.Run("replaces the only token", func(t *testing.T) {
+		fake := &fakeTokens{tokens: []client.APIToken{ciToken}}
+		builder := newTestServiceAccountBuilder(t, fake)
+
+		_, _, err := builder.Rotate(ctx, serviceAccount, randomToken)
+		require.NoError(t, err)
+		require.Equal(t, []string{"ci"}, fake.revoked)
+	})
+
+	t.Run("refuses to rotate when no token was issued by a rotation", func(t *testing.T) {
+		fake := &fakeTokens{tokens: []client.APIToken{ciToken, {ID: "deploy", Name: "Deploy"}}}
+		builder := newTestServiceAccountBuilder(t, fake)
+
+		secrets, _, err := builder.Rotate(ctx, serviceAccount, randomToken)
+		require.Equal(t, codes.FailedPrecondition, status.Code(err))
+		require.Empty(t, secrets)
+		require.Empty(t, fake.revoked)
+		// No new token was issued.
+		require.Len(t, fake.tokens, 2)
+	})
+
+	t.Run("issues the first token", func(t *testing.T) {
+		fake := &fakeTokens{}
+		builder := newTestServiceAccountBuilder(t, fake)
+
+		secrets, _, err := builder.Rotate(ctx, serviceAccount, randomToken)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.Empty(t, fake.revoked)
+		require.Len(t, fake.tokens, 1)
+	})
+
+	t.Run("revokes the new token when the previous one cannot be revoked", func(t *testing.T) {
+		fake := &fakeTokens{tokens: []client.APIToken{ciToken}, failRevoke: "ci"}
+		builder := newTestServiceAccountBuilder(t, fake)
+
+		_, _, err := builder.Rotate(ctx, serviceAccount, randomToken)
+		require.Error(t, err)
+		require.Len(t, fake.revoked, 1)
+		require.Equal(t, []client.APIToken{ciToken}, fake.tokens)
+	})
+}
@@ -0,0 +1,129 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+)
+
+const ownerEntitlement = "owner"
+
+// Create a new connector resource for a tray.ai solution instance.
+func solutionInstanceResource(
+	_ context.Context,
+	instance client.SolutionInstance,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":          instance.ID,
+		"name":        instance.Name,
+		"solution_id": instance.SolutionID,
+		"owner_id":    instance.OwnerID,
+		"enabled":     instance.Enabled,
+	}
+	if !instance.CreatedAt.IsZero() {
+		profile["created_at"] = instance.CreatedAt.Format(time.RFC3339)
+	}
+
+	return resource.NewAppResource(
+		instance.Name,
+		solutionInstanceResourceType,
+		instance.ID,
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+		},
+		resource.WithParentResourceID(parentResourceID),
+	)
+}
+
+type solutionInstanceBuilder struct {
+	client *client.Client
+}
+
+func (o *solutionInstanceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return solutionInstanceResourceType
+}
+
+// List returns the deployed instances of the parent solution as resource objects.
+func (o *solutionInstanceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil || parentResourceID.ResourceType != solutionResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	var (
+		instances []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListEmbeddedSolutionInstances(ctx, client.ListEmbeddedSolutionInstancesParams{
+		SolutionID: parentResourceID.Resource,
+		Cursor:     pToken.Token,
+		First:      pToken.Size,
+	})
+	if unavailable(ctx, err, solutionInstanceResourceType) {
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListEmbeddedSolutionInstances failed: %w", err)
+	}
+
+	for _, instance := range resp.SolutionInstances {
+		vInstance, err := solutionInstanceResource(ctx, instance, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		instances = append(instances, vInstance)
+	}
+
+	return instances, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns the owner entitlement of the solution instance.
+func (o *solutionInstanceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			ownerEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Solution Instance owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Owns the %s solution instance", resource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant of the solution instance to the user who owns it.
+func (o *solutionInstanceBuilder) Grants(ctx context.Context, res *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	appTrait, err := resource.GetAppTrait(res)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: cannot get app trait: %w", err)
+	}
+
+	ownerID, ok := resource.GetProfileStringValue(appTrait.GetProfile(), "owner_id")
+	if !ok || ownerID == "" {
+		return nil, "", nil, nil
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(
+			res,
+			ownerEntitlement,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     ownerID,
+			},
+		),
+	}, "", nil, nil
+}
+
+func newSolutionInstanceBuilder(c *client.Client) *solutionInstanceBuilder {
+	return &solutionInstanceBuilder{
+		client: c,
+	}
+}
@@ -0,0 +1,44 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListSolutionInstances(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		// Solution instances are only exposed by the Embedded GraphQL API.
+		require.Equal(t, "/graphql", r.URL.Path)
+		var req struct {
+			Variables map[string]interface{} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "s1", req.Variables["solutionId"])
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": {"solutionInstances": {
+			"edges": [{"node": {"id": "si1", "name": "Acme Sync", "enabled": true, "created": "2024-05-01T10:00:00Z",
+				"owner": "u1", "solution": {"id": "s1"}}}],
+			"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+		}}}`))
+	})
+
+	ctx := context.Background()
+	builder := newSolutionInstanceBuilder(c.client)
+	solutionID := &v2.ResourceId{ResourceType: solutionResourceType.Id, Resource: "s1"}
+	instances, next, _, err := builder.List(ctx, solutionID, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, "c1", next)
+
+	grants, _, _, err := builder.Grants(ctx, instances[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+}
@@ -0,0 +1,97 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+)
+
+// Create a new connector resource for a tray.ai solution.
+func solutionResource(
+	_ context.Context,
+	solution client.Solution,
+	childTypes []*v2.ResourceType,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":          solution.ID,
+		"title":       solution.Title,
+		"description": solution.Description,
+		"enabled":     solution.Enabled,
+	}
+	if !solution.CreatedAt.IsZero() {
+		profile["created_at"] = solution.CreatedAt.Format(time.RFC3339)
+	}
+
+	return resource.NewAppResource(
+		solution.Title,
+		solutionResourceType,
+		solution.ID,
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+		},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(solution.Description),
+		withChildResourceTypes(childTypes),
+	)
+}
+
+type solutionBuilder struct {
+	client     *client.Client
+	childTypes []*v2.ResourceType
+}
+
+func (o *solutionBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return solutionResourceType
+}
+
+// List returns all the Embedded solutions as resource objects.
+func (o *solutionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		solutions []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListSolutions(ctx, client.ListSolutionsParams{
+		Cursor: pToken.Token,
+		First:  pToken.Size,
+	})
+	if unavailable(ctx, err, solutionResourceType) {
+		return nil, "", nil, nil
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListSolutions failed: %w", err)
+	}
+
+	for _, solution := range resp.Solutions {
+		vSolution, err := solutionResource(ctx, solution, o.childTypes, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		solutions = append(solutions, vSolution)
+	}
+
+	return solutions, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements always returns an empty slice for solutions, access is granted on their instances.
+func (o *solutionBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for solutions since they don't have any entitlements.
+func (o *solutionBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newSolutionBuilder(c *client.Client, childTypes []*v2.ResourceType) *solutionBuilder {
+	return &solutionBuilder{
+		client:     c,
+		childTypes: childTypes,
+	}
+}
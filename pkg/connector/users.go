@@ -2,41 +2,108 @@ package connector
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// Create a new connector resource for a tray.ai user.
+// Create a new connector resource for a tray.ai user. usage may be nil when it couldn't be fetched.
 func userResource(
 	_ context.Context,
 	user client.User,
+	usage *client.UserUsage,
 	parentResourceID *v2.ResourceId,
 ) (*v2.Resource, error) {
-	// TODO. BB-451. We should get the email via GetUser api.
-	// see https://developer.tray.ai/openapi/trayapi/tag/users/#tag/users/operation/get-user-by-id
 	profile := map[string]interface{}{
-		"id":       user.ID,
-		"username": user.Name,
+		"id":                 user.ID,
+		"username":           user.Name,
+		"email":              user.Email,
+		"account_type":       user.Type,
+		"description":        user.Description,
+		"monthly_task_limit": user.MonthlyTaskLimit,
+		"external":           user.IsExternal(),
+		"organization_role":  user.OrgRole,
 	}
+
+	// Embedded end-customer users are people, but not members of the organization. They are told apart from
+	// org members by the external profile field rather than by the account type, which would file them with the
+	// non-human identities.
+	if user.IsExternal() {
+		profile["external_user_id"] = user.ExternalUserID
+	}
+
+	userStatus := v2.UserTrait_Status_STATUS_ENABLED
+	if user.Disabled {
+		userStatus = v2.UserTrait_Status_STATUS_DISABLED
+	}
+
+	traitOptions := []resource.UserTraitOption{
+		resource.WithStatus(userStatus),
+		resource.WithUserProfile(profile),
+		resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
+	}
+	if user.Email != "" {
+		traitOptions = append(traitOptions,
+			resource.WithEmail(user.Email, true),
+			resource.WithUserLogin(user.Email),
+		)
+	}
+
+	if usage != nil {
+		profile["tasks_used"] = usage.TasksUsed
+		if !usage.LastActivityAt.IsZero() {
+			profile["last_activity_at"] = usage.LastActivityAt.Format(time.RFC3339)
+		}
+
+		// Users signing in through SSO may have activity but no recorded login, so fall back to the last activity.
+		lastLogin := usage.LastLoginAt
+		if lastLogin.IsZero() {
+			lastLogin = usage.LastActivityAt
+		}
+		if !lastLogin.IsZero() {
+			traitOptions = append(traitOptions, resource.WithLastLogin(lastLogin))
+		}
+	}
+
 	return resource.NewUserResource(
 		user.Name,
 		userResourceType,
 		user.ID,
-		[]resource.UserTraitOption{
-			resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
-			resource.WithUserProfile(profile),
-		},
+		traitOptions,
 		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(user.Description),
 	)
 }
 
 type userBuilder struct {
 	client *client.Client
+	userBuilderOptions
+}
+
+// userBuilderOptions are the configurable behaviors of the user sync and provisioning.
+type userBuilderOptions struct {
+	// excludeExternalUsers skips the Embedded end-customer users.
+	excludeExternalUsers bool
+	// successorUserID takes over the workspaces owned by a deleted user when set.
+	successorUserID string
+	// syncUsage fetches the login and task usage of every synced user, at the cost of one more call per user.
+	syncUsage bool
+	// syncUsers limits the sync to these users, given by email or ID, when set.
+	syncUsers []string
 }
 
 func (o *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -46,11 +113,16 @@ func (o *userBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 // List returns all the users from the database as resource objects.
 // Users include a UserTrait because they are the 'shape' of a standard user.
 func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if len(o.syncUsers) > 0 {
+		users, err := o.listNamedUsers(ctx, parentResourceID)
+		return users, "", nil, err
+	}
+
 	var (
 		users []*v2.Resource
 	)
 
-	resp, err := o.client.ListUsers(ctx, client.ListUsersParams{
+	resp, annos, err := o.client.ListUsers(ctx, client.ListUsersParams{
 		Cursor: pToken.Token,
 		First:  pToken.Size,
 	})
@@ -59,17 +131,117 @@ func (o *userBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 	}
 
 	for _, user := range resp.Users {
-		vUser, err := userResource(ctx, user, parentResourceID)
+		if o.excludeExternalUsers && user.IsExternal() {
+			continue
+		}
+
+		// The list endpoint doesn't return the email nor the status of the user, so fetch the full user.
+		details, err := o.client.GetUser(ctx, user.ID)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				// The user was removed since the list call.
+				continue
+			}
+			return nil, "", nil, fmt.Errorf("baton-trayai: GetUser failed: %w", err)
+		}
+		if details == nil {
+			return nil, "", nil, status.Errorf(codes.Internal, "baton-trayai: tray.ai returned no body for user %s", user.ID)
+		}
+
+		vUser, err := o.userResourceWithUsage(ctx, *details, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		users = append(users, vUser)
+	}
+
+	return users, resp.Page.NextCursor(), annos, nil
+}
+
+// listNamedUsers returns only the users named in syncUsers, looked up one by one, so refreshing a few users
+// doesn't page through the whole directory. Users that no longer exist are skipped.
+func (o *userBuilder) listNamedUsers(ctx context.Context, parentResourceID *v2.ResourceId) ([]*v2.Resource, error) {
+	l := ctxzap.Extract(ctx)
+
+	users := make([]*v2.Resource, 0, len(o.syncUsers))
+	for _, ref := range o.syncUsers {
+		user, err := o.lookupUser(ctx, ref)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				l.Warn("baton-trayai: skipping unknown user", zap.String("user", ref))
+				continue
+			}
+			return nil, err
+		}
+		if o.excludeExternalUsers && user.IsExternal() {
+			continue
+		}
+
+		vUser, err := o.userResourceWithUsage(ctx, *user, parentResourceID)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+			return nil, err
 		}
 		users = append(users, vUser)
 	}
+	return users, nil
+}
 
-	if !resp.Page.HasNextPage {
-		return users, "", nil, nil
+// lookupUser resolves a single user by email or ID.
+func (o *userBuilder) lookupUser(ctx context.Context, ref string) (*client.User, error) {
+	userID := ref
+	if strings.Contains(ref, "@") {
+		resp, _, err := o.client.ListUsers(ctx, client.ListUsersParams{
+			Email: ref,
+			First: 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("baton-trayai: ListUsers failed: %w", err)
+		}
+		if len(resp.Users) == 0 {
+			return nil, status.Errorf(codes.NotFound, "baton-trayai: no tray.ai user with the email %s", ref)
+		}
+		userID = resp.Users[0].ID
+	}
+
+	// The list endpoint doesn't return the email nor the status of the user, so fetch the full user.
+	user, err := o.client.GetUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-trayai: GetUser failed: %w", err)
 	}
-	return users, resp.Page.EndCursor, nil, nil
+	if user == nil {
+		return nil, status.Errorf(codes.Internal, "baton-trayai: tray.ai returned no body for user %s", userID)
+	}
+	// Guard against the email filter matching loosely.
+	if userID != ref && !strings.EqualFold(user.Email, ref) {
+		return nil, status.Errorf(codes.NotFound, "baton-trayai: no tray.ai user with the email %s", ref)
+	}
+	return user, nil
+}
+
+// userResourceWithUsage fetches the usage of the user, when enabled, and creates its connector resource.
+// tray.ai has no bulk usage endpoint, so the usage is opt-in to keep large tenants at one call per user.
+func (o *userBuilder) userResourceWithUsage(ctx context.Context, user client.User, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	l := ctxzap.Extract(ctx)
+
+	var usage *client.UserUsage
+	if o.syncUsage {
+		var err error
+		usage, err = o.client.GetUserUsage(ctx, user.ID)
+		if err != nil {
+			if code := status.Code(err); code != codes.NotFound && code != codes.PermissionDenied {
+				return nil, fmt.Errorf("baton-trayai: GetUserUsage failed: %w", err)
+			}
+			// Usage isn't tracked for every user nor readable by every token, the user is synced without it.
+			l.Debug("baton-trayai: no usage data for user", zap.String("user_id", user.ID), zap.Error(err))
+			usage = nil
+		}
+	}
+
+	vUser, err := userResource(ctx, user, usage, parentResourceID)
+	if err != nil {
+		return nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+	}
+	return vUser, nil
 }
 
 // Entitlements always returns an empty slice for users.
@@ -82,8 +254,209 @@ func (o *userBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken
 	return nil, "", nil, nil
 }
 
-func newUserBuilder(c *client.Client) *userBuilder {
+// CreateAccount creates a new tray.ai user from the account info. The optional "workspace_id" and
+// "workspace_role" profile fields add the user to a workspace right away.
+func (o *userBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	_ *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	params, err := createUserParams(accountInfo)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	user, err := o.client.CreateUser(ctx, params)
+	if err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			return nil, nil, nil, fmt.Errorf("baton-trayai: a tray.ai user with the email %s already exists: %w", params.Email, err)
+		}
+		return nil, nil, nil, fmt.Errorf("baton-trayai: CreateUser failed: %w", err)
+	}
+	if user == nil {
+		return nil, nil, nil, status.Errorf(codes.Internal, "baton-trayai: tray.ai returned no body for the created user %s", params.Email)
+	}
+
+	// Confirm the account by looking it up by email, so the returned resource reflects the state tray.ai
+	// settled on rather than the create response.
+	verified, err := o.lookupUser(ctx, params.Email)
+	if err != nil {
+		ctxzap.Extract(ctx).Warn("baton-trayai: cannot verify the created user",
+			zap.String("user_id", user.ID),
+			zap.Error(err),
+		)
+	} else {
+		user = verified
+	}
+
+	vUser, err := userResource(ctx, *user, nil, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource:              vUser,
+		IsCreateAccountResult: true,
+	}, nil, nil, nil
+}
+
+// CreateAccountCapabilityDetails returns the credential options supported when creating accounts. tray.ai
+// users sign in through an invitation or SSO, so the connector never sets a password.
+func (o *userBuilder) CreateAccountCapabilityDetails(_ context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_SSO,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
+}
+
+// Delete removes the user from the tray.ai organization. When a successor is configured, the workspaces the
+// user owns are handed over to the successor first so their automations aren't orphaned. Solutions aren't
+// transferred: they belong to the organization rather than to a member, and their instances are owned by
+// Embedded end-customer users, which tray.ai offers no way to reassign.
+func (o *userBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("baton-trayai: cannot delete non-user resource %s", resourceId.ResourceType)
+	}
+	userID := resourceId.Resource
+
+	if o.successorUserID != "" {
+		if o.successorUserID == userID {
+			return nil, fmt.Errorf("baton-trayai: cannot delete the configured successor user %s", userID)
+		}
+		if err := o.transferOwnedWorkspaces(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	err := o.client.DeleteUser(ctx, userID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("baton-trayai: DeleteUser failed: %w", err)
+	}
+	return nil, nil
+}
+
+// transferOwnedWorkspaces makes the successor an owner of every workspace owned by the user.
+func (o *userBuilder) transferOwnedWorkspaces(ctx context.Context, userID string) error {
+	l := ctxzap.Extract(ctx)
+
+	cursor := ""
+	for {
+		resp, _, err := o.client.ListWorkspaces(ctx, client.ListWorkspacesParams{Cursor: cursor})
+		if err != nil {
+			return fmt.Errorf("baton-trayai: ListWorkspaces failed: %w", err)
+		}
+
+		for _, workspace := range resp.Workspaces {
+			member, err := o.client.GetWorkspaceMember(ctx, workspace.ID, userID, client.WithoutCache())
+			if err != nil {
+				if status.Code(err) == codes.NotFound {
+					continue
+				}
+				return fmt.Errorf("baton-trayai: GetWorkspaceMember failed: %w", err)
+			}
+			if member.Role != client.WorkspaceRoleOwner {
+				continue
+			}
+
+			l.Info("baton-trayai: transferring workspace ownership to successor",
+				zap.String("workspace_id", workspace.ID),
+				zap.String("user_id", userID),
+				zap.String("successor_user_id", o.successorUserID),
+			)
+			if err := o.setWorkspaceOwner(ctx, workspace.ID, o.successorUserID); err != nil {
+				return err
+			}
+		}
+
+		cursor = resp.Page.NextCursor()
+		if cursor == "" {
+			return nil
+		}
+	}
+}
+
+// setWorkspaceOwner gives the owner role of the workspace to the user, adding them to it if needed.
+func (o *userBuilder) setWorkspaceOwner(ctx context.Context, workspaceID, userID string) error {
+	member, err := o.client.GetWorkspaceMember(ctx, workspaceID, userID, client.WithoutCache())
+	switch {
+	case err == nil && member.Role == client.WorkspaceRoleOwner:
+		return nil
+	case err == nil:
+		if err := o.client.UpdateWorkspaceMemberRole(ctx, workspaceID, userID, client.WorkspaceRoleOwner); err != nil {
+			return fmt.Errorf("baton-trayai: UpdateWorkspaceMemberRole failed: %w", err)
+		}
+	case status.Code(err) == codes.NotFound:
+		if err := o.client.AddWorkspaceMember(ctx, workspaceID, userID, client.WorkspaceRoleOwner); err != nil {
+			return fmt.Errorf("baton-trayai: AddWorkspaceMember failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("baton-trayai: GetWorkspaceMember failed: %w", err)
+	}
+	return nil
+}
+
+// createUserParams maps the account info profile onto the tray.ai create-user params.
+func createUserParams(accountInfo *v2.AccountInfo) (client.CreateUserParams, error) {
+	profile := accountInfo.GetProfile()
+
+	email := primaryEmail(accountInfo)
+	if email == "" {
+		email, _ = resource.GetProfileStringValue(profile, "email")
+	}
+	if email == "" {
+		return client.CreateUserParams{}, fmt.Errorf("baton-trayai: an email is required to create a user")
+	}
+
+	name, _ := resource.GetProfileStringValue(profile, "name")
+	if name == "" {
+		firstName, _ := resource.GetProfileStringValue(profile, "first_name")
+		lastName, _ := resource.GetProfileStringValue(profile, "last_name")
+		name = strings.TrimSpace(firstName + " " + lastName)
+	}
+	if name == "" {
+		name = email
+	}
+
+	workspaceID, _ := resource.GetProfileStringValue(profile, "workspace_id")
+	role, _ := resource.GetProfileStringValue(profile, "workspace_role")
+	switch {
+	case workspaceID == "" && role != "":
+		return client.CreateUserParams{}, fmt.Errorf("baton-trayai: workspace_role requires a workspace_id")
+	case workspaceID != "" && role == "":
+		role = client.WorkspaceRoleViewer
+	case role != "" && !slices.Contains(workspaceRoles, role):
+		return client.CreateUserParams{}, fmt.Errorf("baton-trayai: invalid workspace_role %q", role)
+	}
+
+	return client.CreateUserParams{
+		Name:        name,
+		Email:       email,
+		WorkspaceID: workspaceID,
+		Role:        role,
+	}, nil
+}
+
+// primaryEmail returns the primary email of the account, or its first email when none is marked primary.
+func primaryEmail(accountInfo *v2.AccountInfo) string {
+	emails := accountInfo.GetEmails()
+	for _, email := range emails {
+		if email.GetIsPrimary() {
+			return email.GetAddress()
+		}
+	}
+	if len(emails) > 0 {
+		return emails[0].GetAddress()
+	}
+	return ""
+}
+
+func newUserBuilder(c *client.Client, opts userBuilderOptions) *userBuilder {
 	return &userBuilder{
-		client: c,
+		client:             c,
+		userBuilderOptions: opts,
 	}
 }
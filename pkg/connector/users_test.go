@@ -0,0 +1,350 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestListNamedUsers(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/core/v1/users":
+			// Paging through the directory would list every user, only the email lookup is expected.
+			require.Equal(t, "ada@example.com", r.URL.Query().Get("email"))
+			require.Equal(t, "1", r.URL.Query().Get("first"))
+			_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {}}`))
+		case "/core/v1/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "name": "Ada", "email": "Ada@example.com"}`))
+		case "/core/v1/users/u2":
+			_, _ = w.Write([]byte(`{"id": "u2", "name": "Grace", "email": "grace@example.com", "disabled": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	builder := newUserBuilder(c.client, userBuilderOptions{syncUsers: []string{"ada@example.com", "u2", "gone"}})
+	users, next, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Empty(t, next)
+	require.Len(t, users, 2)
+	require.Equal(t, "u1", users[0].Id.Resource)
+	require.Equal(t, "u2", users[1].Id.Resource)
+
+	trait, err := resource.GetUserTrait(users[1])
+	require.NoError(t, err)
+	require.Equal(t, "STATUS_DISABLED", trait.GetStatus().GetStatus().String())
+}
+
+func TestExternalUserResource(t *testing.T) {
+	user := client.User{ID: "u1", Name: "Acme", Type: client.UserTypeExternal, ExternalUserID: "acme-1"}
+	vUser, err := userResource(context.Background(), user, nil, nil)
+	require.NoError(t, err)
+
+	userTrait, err := resource.GetUserTrait(vUser)
+	require.NoError(t, err)
+	// End-customer users are people, kept apart from org members by the profile rather than the account type.
+	require.Equal(t, v2.UserTrait_ACCOUNT_TYPE_HUMAN, userTrait.GetAccountType())
+	require.True(t, userTrait.GetProfile().GetFields()["external"].GetBoolValue())
+	externalID, _ := resource.GetProfileStringValue(userTrait.GetProfile(), "external_user_id")
+	require.Equal(t, "acme-1", externalID)
+}
+
+func TestListUsersFetchesUsageOnlyWhenEnabled(t *testing.T) {
+	usageCalls := 0
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/core/v1/users":
+			_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {}}`))
+		case "/core/v1/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "name": "Ada", "email": "ada@example.com"}`))
+		case "/core/v1/users/u1/usage":
+			usageCalls++
+			_, _ = w.Write([]byte(`{"tasksUsed": 42, "lastLoginAt": "2026-10-01T12:00:00Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	users, _, _, err := newUserBuilder(c.client, userBuilderOptions{}).List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Zero(t, usageCalls)
+	trait, err := resource.GetUserTrait(users[0])
+	require.NoError(t, err)
+	require.Nil(t, trait.GetLastLogin())
+
+	users, _, _, err = newUserBuilder(c.client, userBuilderOptions{syncUsage: true}).List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.Equal(t, 1, usageCalls)
+	trait, err = resource.GetUserTrait(users[0])
+	require.NoError(t, err)
+	require.Equal(t, int64(42), int64(trait.GetProfile().GetFields()["tasks_used"].GetNumberValue()))
+	require.NotNil(t, trait.GetLastLogin())
+}
+
+func TestListUsersRejectsEmptyUser(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/core/v1/users":
+			_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {}}`))
+		case "/core/v1/users/u1":
+			_, _ = w.Write([]byte(`null`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	_, _, _, err := newUserBuilder(c.client, userBuilderOptions{}).List(ctx, nil, &pagination.Token{})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	_, _, _, err = newUserBuilder(c.client, userBuilderOptions{syncUsers: []string{"u1"}}).List(ctx, nil, &pagination.Token{})
+	require.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestCreateUserParams(t *testing.T) {
+	testCases := []struct {
+		name    string
+		emails  []*v2.AccountInfo_Email
+		profile map[string]interface{}
+		want    client.CreateUserParams
+		wantErr string
+	}{
+		{
+			name: "primary email and full name",
+			emails: []*v2.AccountInfo_Email{
+				{Address: "other@example.com"},
+				{Address: "ada@example.com", IsPrimary: true},
+			},
+			profile: map[string]interface{}{"name": "Ada Lovelace"},
+			want:    client.CreateUserParams{Name: "Ada Lovelace", Email: "ada@example.com"},
+		},
+		{
+			name:    "email and name from the profile",
+			profile: map[string]interface{}{"email": "ada@example.com", "first_name": "Ada", "last_name": "Lovelace"},
+			want:    client.CreateUserParams{Name: "Ada Lovelace", Email: "ada@example.com"},
+		},
+		{
+			name:   "name falls back to the email",
+			emails: []*v2.AccountInfo_Email{{Address: "ada@example.com"}},
+			want:   client.CreateUserParams{Name: "ada@example.com", Email: "ada@example.com"},
+		},
+		{
+			name:    "workspace defaults to the viewer role",
+			emails:  []*v2.AccountInfo_Email{{Address: "ada@example.com"}},
+			profile: map[string]interface{}{"workspace_id": "ws1"},
+			want: client.CreateUserParams{
+				Name:        "ada@example.com",
+				Email:       "ada@example.com",
+				WorkspaceID: "ws1",
+				Role:        client.WorkspaceRoleViewer,
+			},
+		},
+		{
+			name:    "workspace with a role",
+			emails:  []*v2.AccountInfo_Email{{Address: "ada@example.com"}},
+			profile: map[string]interface{}{"workspace_id": "ws1", "workspace_role": client.WorkspaceRoleAdmin},
+			want: client.CreateUserParams{
+				Name:        "ada@example.com",
+				Email:       "ada@example.com",
+				WorkspaceID: "ws1",
+				Role:        client.WorkspaceRoleAdmin,
+			},
+		},
+		{
+			name:    "missing email",
+			profile: map[string]interface{}{"name": "Ada"},
+			wantErr: "an email is required",
+		},
+		{
+			name:    "role without a workspace",
+			emails:  []*v2.AccountInfo_Email{{Address: "ada@example.com"}},
+			profile: map[string]interface{}{"workspace_role": client.WorkspaceRoleAdmin},
+			wantErr: "workspace_role requires a workspace_id",
+		},
+		{
+			name:    "unknown role",
+			emails:  []*v2.AccountInfo_Email{{Address: "ada@example.com"}},
+			profile: map[string]interface{}{"workspace_id": "ws1", "workspace_role": "superuser"},
+			wantErr: "invalid workspace_role",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, err := structpb.NewStruct(tc.profile)
+			require.NoError(t, err)
+
+			params, err := createUserParams(&v2.AccountInfo{Emails: tc.emails, Profile: profile})
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, params)
+		})
+	}
+}
+
+func TestCreateAccount(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/core/v1/users":
+			var body client.CreateUserParams
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			switch body.Email {
+			case "taken@example.com":
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"code": "user_already_exists", "message": "user already exists"}`))
+			case "null@example.com":
+				_, _ = w.Write([]byte(`null`))
+			case "ada@example.com":
+				require.Equal(t, "ws1", body.WorkspaceID)
+				require.Equal(t, client.WorkspaceRoleViewer, body.Role)
+				_, _ = w.Write([]byte(`{"id": "u1", "name": "Ada", "email": "ada@example.com"}`))
+			default:
+				_, _ = w.Write([]byte(`{"id": "u2", "name": "Grace", "email": "` + body.Email + `"}`))
+			}
+		case r.Method == http.MethodGet && r.URL.Path == "/core/v1/users":
+			if r.URL.Query().Get("email") == "ada@example.com" {
+				_, _ = w.Write([]byte(`{"elements": [{"id": "u1"}], "pageInfo": {}}`))
+				return
+			}
+			// The new user isn't searchable yet.
+			_, _ = w.Write([]byte(`{"elements": [], "pageInfo": {}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/core/v1/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "name": "Ada Lovelace", "email": "ada@example.com"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+	b := newUserBuilder(c.client, userBuilderOptions{})
+
+	accountInfo := func(email string, profile map[string]interface{}) *v2.AccountInfo {
+		p, err := structpb.NewStruct(profile)
+		require.NoError(t, err)
+		return &v2.AccountInfo{Emails: []*v2.AccountInfo_Email{{Address: email, IsPrimary: true}}, Profile: p}
+	}
+
+	// The returned resource is the user looked up after the create call.
+	resp, _, _, err := b.CreateAccount(ctx, accountInfo("ada@example.com", map[string]interface{}{"workspace_id": "ws1"}), nil)
+	require.NoError(t, err)
+	result, ok := resp.(*v2.CreateAccountResponse_SuccessResult)
+	require.True(t, ok)
+	require.Equal(t, "u1", result.Resource.Id.Resource)
+	require.Equal(t, "Ada Lovelace", result.Resource.DisplayName)
+
+	// When the lookup doesn't find the user yet, the create response is used.
+	resp, _, _, err = b.CreateAccount(ctx, accountInfo("grace@example.com", nil), nil)
+	require.NoError(t, err)
+	result, ok = resp.(*v2.CreateAccountResponse_SuccessResult)
+	require.True(t, ok)
+	require.Equal(t, "u2", result.Resource.Id.Resource)
+	require.Equal(t, "Grace", result.Resource.DisplayName)
+
+	_, _, _, err = b.CreateAccount(ctx, accountInfo("taken@example.com", nil), nil)
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+	require.ErrorContains(t, err, "taken@example.com already exists")
+
+	_, _, _, err = b.CreateAccount(ctx, accountInfo("null@example.com", nil), nil)
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	// Invalid account info is rejected before calling tray.ai.
+	_, _, _, err = b.CreateAccount(ctx, accountInfo("ada@example.com", map[string]interface{}{"workspace_role": "owner"}), nil)
+	require.ErrorContains(t, err, "workspace_role requires a workspace_id")
+}
+
+func TestDeleteUser(t *testing.T) {
+	var calls []string
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		call := r.Method + " " + r.URL.Path
+		switch call {
+		case "GET /core/v1/workspaces":
+			if r.URL.Query().Get("cursor") == "" {
+				_, _ = w.Write([]byte(`{
+					"elements": [{"id": "ws1"}, {"id": "ws2"}],
+					"pageInfo": {"endCursor": "page2", "hasNextPage": true}
+				}`))
+				return
+			}
+			require.Equal(t, "page2", r.URL.Query().Get("cursor"))
+			_, _ = w.Write([]byte(`{"elements": [{"id": "ws3"}, {"id": "ws4"}], "pageInfo": {}}`))
+		case "GET /core/v1/workspaces/ws1/users/u1", "GET /core/v1/workspaces/ws2/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "role": "owner"}`))
+		case "GET /core/v1/workspaces/ws3/users/u1":
+			_, _ = w.Write([]byte(`{"id": "u1", "role": "contributor"}`))
+		case "GET /core/v1/workspaces/ws2/users/heir":
+			_, _ = w.Write([]byte(`{"id": "heir", "role": "contributor"}`))
+		case "POST /core/v1/workspaces/ws1/users":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, map[string]string{"userId": "heir", "role": "owner"}, body)
+			calls = append(calls, call)
+			_, _ = w.Write([]byte(`{}`))
+		case "PATCH /core/v1/workspaces/ws2/users/heir":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			require.Equal(t, "owner", body["role"])
+			calls = append(calls, call)
+			_, _ = w.Write([]byte(`{}`))
+		case "DELETE /core/v1/users/u1":
+			calls = append(calls, call)
+			w.WriteHeader(http.StatusNoContent)
+		case "DELETE /core/v1/users/gone":
+			calls = append(calls, call)
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			if r.Method != http.MethodGet {
+				t.Errorf("unexpected request %s", call)
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ctx := context.Background()
+
+	// The successor is added to, or promoted in, the workspaces the user owns before the user is deleted.
+	b := newUserBuilder(c.client, userBuilderOptions{successorUserID: "heir"})
+	_, err := b.Delete(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "u1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"POST /core/v1/workspaces/ws1/users",
+		"PATCH /core/v1/workspaces/ws2/users/heir",
+		"DELETE /core/v1/users/u1",
+	}, calls)
+
+	_, err = b.Delete(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "heir"})
+	require.ErrorContains(t, err, "cannot delete the configured successor user")
+
+	// A user that no longer exists counts as deleted, so retries are safe.
+	calls = nil
+	b = newUserBuilder(c.client, userBuilderOptions{})
+	_, err = b.Delete(ctx, &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "gone"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"DELETE /core/v1/users/gone"}, calls)
+
+	_, err = b.Delete(ctx, &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "ws1"})
+	require.ErrorContains(t, err, "cannot delete non-user resource")
+}
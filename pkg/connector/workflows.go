@@ -0,0 +1,155 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+)
+
+// Create a new connector resource for a tray.ai workflow.
+func workflowResource(
+	_ context.Context,
+	workflow client.Workflow,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	connectors := make([]interface{}, 0, len(workflow.Connectors))
+	for _, connector := range workflow.Connectors {
+		connectors = append(connectors, connector)
+	}
+
+	profile := map[string]interface{}{
+		"id":               workflow.ID,
+		"name":             workflow.Name,
+		"workspace_id":     workflow.WorkspaceID,
+		"project_id":       workflow.ProjectID,
+		"enabled":          workflow.Enabled,
+		"trigger_type":     workflow.TriggerType,
+		"connectors":       connectors,
+		"created_by":       workflow.CreatedBy,
+		"last_modified_by": workflow.LastModifiedBy,
+	}
+	if !workflow.CreatedAt.IsZero() {
+		profile["created_at"] = workflow.CreatedAt.Format(time.RFC3339)
+	}
+	if !workflow.UpdatedAt.IsZero() {
+		profile["updated_at"] = workflow.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return resource.NewAppResource(
+		workflow.Name,
+		workflowResourceType,
+		workflow.ID,
+		[]resource.AppTraitOption{
+			resource.WithAppProfile(profile),
+		},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(workflow.Description),
+	)
+}
+
+type workflowBuilder struct {
+	client *client.Client
+	// underProjects is set when projects are synced, so the workflows of a project are listed under it rather
+	// than under its workspace.
+	underProjects bool
+}
+
+func (o *workflowBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return workflowResourceType
+}
+
+// List returns the workflows of the parent project, or the workflows of the parent workspace that belong to no
+// project, as resource objects.
+func (o *workflowBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	params := client.ListWorkflowsParams{
+		Cursor: pToken.Token,
+		First:  pToken.Size,
+	}
+	switch parentResourceID.ResourceType {
+	case projectResourceType.Id:
+		params.ProjectID = parentResourceID.Resource
+	case workspaceResourceType.Id:
+		params.WorkspaceID = parentResourceID.Resource
+	default:
+		return nil, "", nil, nil
+	}
+
+	var (
+		workflows []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListWorkflows(ctx, params)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListWorkflows failed: %w", err)
+	}
+
+	for _, workflow := range resp.Workflows {
+		// The workflows of a project are listed under the project.
+		if params.WorkspaceID != "" && workflow.ProjectID != "" && o.underProjects {
+			continue
+		}
+		vWorkflow, err := workflowResource(ctx, workflow, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		workflows = append(workflows, vWorkflow)
+	}
+
+	return workflows, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns the owner entitlement of the workflow.
+func (o *workflowBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			resource,
+			ownerEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Workflow owner", resource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Built and is responsible for the %s workflow", resource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns the owner grant of the workflow to the user who created it.
+func (o *workflowBuilder) Grants(ctx context.Context, res *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	appTrait, err := resource.GetAppTrait(res)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: cannot get app trait: %w", err)
+	}
+
+	ownerID, ok := resource.GetProfileStringValue(appTrait.GetProfile(), "created_by")
+	if !ok || ownerID == "" {
+		return nil, "", nil, nil
+	}
+
+	return []*v2.Grant{
+		grant.NewGrant(
+			res,
+			ownerEntitlement,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     ownerID,
+			},
+		),
+	}, "", nil, nil
+}
+
+func newWorkflowBuilder(c *client.Client, underProjects bool) *workflowBuilder {
+	return &workflowBuilder{
+		client:        c,
+		underProjects: underProjects,
+	}
+}
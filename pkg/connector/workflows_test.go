@@ -0,0 +1,49 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListWorkflows(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/workflows", r.URL.Path)
+		require.Equal(t, "w1", r.URL.Query().Get("workspaceId"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "wf1", "name": "Sync leads", "workspaceId": "w1", "enabled": true, "triggerType": "scheduled",
+				 "connectors": ["salesforce", "slack"], "createdBy": "u1", "lastModifiedBy": "u2",
+				 "createdAt": "2024-05-01T10:00:00Z", "updatedAt": "2024-05-02T10:00:00Z"},
+				{"id": "wf2", "name": "Project workflow", "workspaceId": "w1", "projectId": "p1", "createdBy": "u1"}
+			],
+			"pageInfo": {}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newWorkflowBuilder(c.client, true)
+	workspaceID := &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "w1"}
+	workflows, _, _, err := builder.List(ctx, workspaceID, &pagination.Token{})
+	require.NoError(t, err)
+	// The project workflow is listed under its project instead.
+	require.Len(t, workflows, 1)
+
+	appTrait, err := resource.GetAppTrait(workflows[0])
+	require.NoError(t, err)
+	modifiedBy, _ := resource.GetProfileStringValue(appTrait.GetProfile(), "last_modified_by")
+	require.Equal(t, "u2", modifiedBy)
+	require.Len(t, appTrait.GetProfile().GetFields()["connectors"].GetListValue().GetValues(), 2)
+
+	grants, _, _, err := builder.Grants(ctx, workflows[0], &pagination.Token{})
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+}
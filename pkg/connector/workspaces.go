@@ -0,0 +1,254 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// workspaceRoles are the roles a user can hold in a workspace. Each one is exposed as an entitlement.
+var workspaceRoles = []string{
+	client.WorkspaceRoleOwner,
+	client.WorkspaceRoleAdmin,
+	client.WorkspaceRoleContributor,
+	client.WorkspaceRoleViewer,
+}
+
+// Create a new connector resource for a tray.ai workspace. childTypes are the synced resource types nested
+// under workspaces.
+func workspaceResource(
+	_ context.Context,
+	workspace client.Workspace,
+	childTypes []*v2.ResourceType,
+	parentResourceID *v2.ResourceId,
+) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":          workspace.ID,
+		"name":        workspace.Name,
+		"type":        workspace.Type,
+		"description": workspace.Description,
+	}
+	return resource.NewGroupResource(
+		workspace.Name,
+		workspaceResourceType,
+		workspace.ID,
+		[]resource.GroupTraitOption{
+			resource.WithGroupProfile(profile),
+		},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(workspace.Description),
+		withChildResourceTypes(childTypes),
+	)
+}
+
+type workspaceBuilder struct {
+	client     *client.Client
+	filter     workspaceFilter
+	childTypes []*v2.ResourceType
+}
+
+func (o *workspaceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return workspaceResourceType
+}
+
+// List returns all the workspaces of the organization as resource objects.
+func (o *workspaceBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	var (
+		workspaces []*v2.Resource
+	)
+
+	resp, annos, err := o.client.ListWorkspaces(ctx, client.ListWorkspacesParams{
+		Cursor: pToken.Token,
+		First:  pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListWorkspaces failed: %w", err)
+	}
+
+	for _, workspace := range resp.Workspaces {
+		// Skipping a workspace also skips its projects, workflows and authentications, which are only listed under it.
+		if !o.filter.matches(workspace) {
+			continue
+		}
+		vWorkspace, err := workspaceResource(ctx, workspace, o.childTypes, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("baton-trayai: cannot create connector resource: %w", err)
+		}
+		workspaces = append(workspaces, vWorkspace)
+	}
+
+	return workspaces, resp.Page.NextCursor(), annos, nil
+}
+
+// Entitlements returns one entitlement per workspace role.
+func (o *workspaceBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	entitlements := make([]*v2.Entitlement, 0, len(workspaceRoles))
+	for _, role := range workspaceRoles {
+		entitlements = append(entitlements, entitlement.NewPermissionEntitlement(
+			resource,
+			role,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Workspace %s", resource.DisplayName, role)),
+			entitlement.WithDescription(fmt.Sprintf("Has the %s role in the %s workspace", role, resource.DisplayName)),
+		))
+	}
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant of the matching role entitlement for every member of the workspace.
+func (o *workspaceBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var (
+		grants []*v2.Grant
+	)
+
+	resp, annos, err := o.client.ListWorkspaceMembers(ctx, client.ListWorkspaceMembersParams{
+		WorkspaceID: resource.Id.Resource,
+		Cursor:      pToken.Token,
+		First:       pToken.Size,
+	})
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("baton-trayai: ListWorkspaceMembers failed: %w", err)
+	}
+
+	for _, member := range resp.Members {
+		if !slices.Contains(workspaceRoles, member.Role) {
+			l.Debug("baton-trayai: skipping workspace member with unknown role",
+				zap.String("workspace_id", resource.Id.Resource),
+				zap.String("user_id", member.ID),
+				zap.String("role", member.Role),
+			)
+			continue
+		}
+		grants = append(grants, grant.NewGrant(
+			resource,
+			member.Role,
+			&v2.ResourceId{
+				ResourceType: userResourceType.Id,
+				Resource:     member.ID,
+			},
+		))
+	}
+
+	return grants, resp.Page.NextCursor(), annos, nil
+}
+
+// Grant adds the principal to the workspace with the role of the entitlement. A user who is already a member
+// of the workspace has their role replaced, since tray.ai users hold a single role per workspace.
+func (o *workspaceBuilder) Grant(ctx context.Context, principal *v2.Resource, ent *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		return nil, nil, fmt.Errorf("baton-trayai: only users can be granted workspace roles, got %s", principal.Id.ResourceType)
+	}
+
+	workspaceID := ent.Resource.Id.Resource
+	userID := principal.Id.Resource
+	role, err := workspaceRoleFromEntitlement(ent)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	grants := []*v2.Grant{grant.NewGrant(ent.Resource, role, principal.Id)}
+
+	// The cached membership is the one the last sync saw, so the current one is read from tray.ai.
+	member, err := o.client.GetWorkspaceMember(ctx, workspaceID, userID, client.WithoutCache())
+	switch {
+	case err == nil && member.Role == role:
+		return grants, annotations.New(&v2.GrantAlreadyExists{}), nil
+	case err == nil:
+		l.Debug("baton-trayai: replacing workspace role",
+			zap.String("workspace_id", workspaceID),
+			zap.String("user_id", userID),
+			zap.String("old_role", member.Role),
+			zap.String("new_role", role),
+		)
+		if err := o.client.UpdateWorkspaceMemberRole(ctx, workspaceID, userID, role); err != nil {
+			return nil, nil, fmt.Errorf("baton-trayai: UpdateWorkspaceMemberRole failed: %w", err)
+		}
+	case status.Code(err) == codes.NotFound:
+		err := o.client.AddWorkspaceMember(ctx, workspaceID, userID, role)
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			// The user joined the workspace since it was checked, so set the role of the existing membership.
+			err = o.client.UpdateWorkspaceMemberRole(ctx, workspaceID, userID, role)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("baton-trayai: AddWorkspaceMember failed: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("baton-trayai: GetWorkspaceMember failed: %w", err)
+	}
+
+	return grants, nil, nil
+}
+
+// Revoke removes the principal from the workspace. Members who are already gone, or who no longer hold the
+// revoked role, are reported as already revoked.
+func (o *workspaceBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	principal := g.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("baton-trayai: only users can be revoked from workspace roles, got %s", principal.Id.ResourceType)
+	}
+
+	workspaceID := g.Entitlement.Resource.Id.Resource
+	userID := principal.Id.Resource
+	role, err := workspaceRoleFromEntitlement(g.Entitlement)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := o.client.GetWorkspaceMember(ctx, workspaceID, userID, client.WithoutCache())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("baton-trayai: GetWorkspaceMember failed: %w", err)
+	}
+	if member.Role != role {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	if err := o.client.RemoveWorkspaceMember(ctx, workspaceID, userID); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+		return nil, fmt.Errorf("baton-trayai: RemoveWorkspaceMember failed: %w", err)
+	}
+
+	return nil, nil
+}
+
+// workspaceRoleFromEntitlement extracts the workspace role from an entitlement ID of the form
+// "workspace:<workspace id>:<role>".
+func workspaceRoleFromEntitlement(ent *v2.Entitlement) (string, error) {
+	parts := strings.Split(ent.Id, ":")
+	role := parts[len(parts)-1]
+	if !slices.Contains(workspaceRoles, role) {
+		return "", fmt.Errorf("baton-trayai: invalid workspace entitlement %q", ent.Id)
+	}
+	return role, nil
+}
+
+func newWorkspaceBuilder(c *client.Client, filter workspaceFilter, childTypes []*v2.ResourceType) *workspaceBuilder {
+	return &workspaceBuilder{
+		client:     c,
+		filter:     filter,
+		childTypes: childTypes,
+	}
+}
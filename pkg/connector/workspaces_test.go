@@ -0,0 +1,252 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-trayai/pkg/connector/client"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListWorkspaces(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/workspaces", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("cursor") == "" {
+			_, _ = w.Write([]byte(`{
+				"elements": [
+					{"id": "w1", "name": "Sales", "type": "organization", "description": "Sales automations"},
+					{"id": "w2", "name": "sandbox-ada", "type": "personal"}
+				],
+				"pageInfo": {"endCursor": "c1", "hasNextPage": true}
+			}`))
+			return
+		}
+		require.Equal(t, "c1", r.URL.Query().Get("cursor"))
+		_, _ = w.Write([]byte(`{"elements": [{"id": "w3", "name": "Support"}], "pageInfo": {"hasNextPage": false}}`))
+	})
+
+	ctx := context.Background()
+	builder := newWorkspaceBuilder(c.client, workspaceFilter{exclude: []string{"sandbox-*"}}, []*v2.ResourceType{projectResourceType})
+	workspaces, next, _, err := builder.List(ctx, nil, &pagination.Token{})
+	require.NoError(t, err)
+	// The excluded workspace is skipped.
+	require.Len(t, workspaces, 1)
+	require.Equal(t, "c1", next)
+
+	require.Equal(t, "w1", workspaces[0].Id.Resource)
+	require.Equal(t, "Sales", workspaces[0].DisplayName)
+	require.Equal(t, "Sales automations", workspaces[0].Description)
+	trait, err := resource.GetGroupTrait(workspaces[0])
+	require.NoError(t, err)
+	workspaceType, _ := resource.GetProfileStringValue(trait.GetProfile(), "type")
+	require.Equal(t, "organization", workspaceType)
+
+	workspaces, next, _, err = builder.List(ctx, nil, &pagination.Token{Token: next})
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, "w3", workspaces[0].Id.Resource)
+	require.Empty(t, next)
+}
+
+func TestWorkspaceEntitlementsAndGrants(t *testing.T) {
+	c := newTestConnector(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/core/v1/workspaces/w1/users", r.URL.Path)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"elements": [
+				{"id": "u1", "role": "owner"},
+				{"id": "u2", "role": "contributor"},
+				{"id": "u3", "role": "guest"},
+				{"id": "u4", "role": "viewer"}
+			],
+			"pageInfo": {}
+		}`))
+	})
+
+	ctx := context.Background()
+	builder := newWorkspaceBuilder(c.client, workspaceFilter{}, nil)
+	workspace := &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: workspaceResourceType.Id, Resource: "w1"},
+		DisplayName: "Sales",
+	}
+
+	entitlements, _, _, err := builder.Entitlements(ctx, workspace, &pagination.Token{})
+	require.NoError(t, err)
+	var ids []string
+	for _, ent := range entitlements {
+		ids = append(ids, ent.Id)
+	}
+	require.Equal(t, []string{"workspace:w1:owner", "workspace:w1:admin", "workspace:w1:contributor", "workspace:w1:viewer"}, ids)
+	require.Equal(t, "Sales Workspace admin", entitlements[1].DisplayName)
+
+	grants, _, _, err := builder.Grants(ctx, workspace, &pagination.Token{})
+	require.NoError(t, err)
+	// The member with a role the connector doesn't know is skipped.
+	require.Len(t, grants, 3)
+	require.Equal(t, "workspace:w1:owner", grants[0].Entitlement.Id)
+	require.Equal(t, "u1", grants[0].Principal.Id.Resource)
+	require.Equal(t, userResourceType.Id, grants[0].Principal.Id.ResourceType)
+	require.Equal(t, "workspace:w1:contributor", grants[1].Entitlement.Id)
+	require.Equal(t, "u2", grants[1].Principal.Id.Resource)
+	require.Equal(t, "workspace:w1:viewer", grants[2].Entitlement.Id)
+	require.Equal(t, "u4", grants[2].Principal.Id.Resource)
+}
+
+// fakeWorkspace serves the membership endpoints of workspace w1 from members, a map of user IDs to roles.
+type fakeWorkspace struct {
+	mu      sync.Mutex
+	members map[string]string
+	// joinOnAdd makes the next add fail with a conflict, as if the user joined the workspace meanwhile.
+	joinOnAdd bool
+	calls     []string
+}
+
+func (f *fakeWorkspace) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, r.Method)
+	w.Header().Set("Content-Type", "application/json")
+	userID := strings.TrimPrefix(r.URL.Path, "/core/v1/workspaces/w1/users/")
+
+	var body struct {
+		UserID string `json:"userId"`
+		Role   string `json:"role"`
+	}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		role, ok := f.members[userID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(client.WorkspaceMember{ID: userID, Role: role})
+	case http.MethodPost:
+		if f.joinOnAdd {
+			f.joinOnAdd = false
+			f.members[body.UserID] = client.WorkspaceRoleViewer
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.members[body.UserID] = body.Role
+		_, _ = w.Write([]byte(`{}`))
+	case http.MethodPatch:
+		f.members[userID] = body.Role
+		_, _ = w.Write([]byte(`{}`))
+	case http.MethodDelete:
+		delete(f.members, userID)
+		_, _ = w.Write([]byte(`{}`))
+	}
+}
+
+// newTestWorkspaceBuilder returns a builder serving workspace w1 from fake. The HTTP cache is enabled, since the
+// membership checks must not be answered from it.
+func newTestWorkspaceBuilder(t *testing.T, fake *fakeWorkspace) (*workspaceBuilder, *v2.Resource) {
+	c := newCachingTestConnector(t, fake.ServeHTTP)
+
+	workspace, err := workspaceResource(context.Background(), client.Workspace{ID: "w1", Name: "Sales"}, nil, nil)
+	require.NoError(t, err)
+	return newWorkspaceBuilder(c.client, workspaceFilter{}, nil), workspace
+}
+
+func TestWorkspaceGrant(t *testing.T) {
+	ctx := context.Background()
+	user := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "u1"}}
+
+	t.Run("adds a new member", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		_, annos, err := builder.Grant(ctx, user, entitlement.NewAssignmentEntitlement(workspace, client.WorkspaceRoleAdmin))
+		require.NoError(t, err)
+		require.Empty(t, annos)
+		require.Equal(t, client.WorkspaceRoleAdmin, fake.members["u1"])
+	})
+
+	t.Run("reports a held role", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{"u1": client.WorkspaceRoleAdmin}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		_, annos, err := builder.Grant(ctx, user, entitlement.NewAssignmentEntitlement(workspace, client.WorkspaceRoleAdmin))
+		require.NoError(t, err)
+		require.True(t, annos.Contains(&v2.GrantAlreadyExists{}))
+		require.Equal(t, []string{http.MethodGet}, fake.calls)
+	})
+
+	t.Run("replaces the role of a member", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{"u1": client.WorkspaceRoleViewer}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		_, _, err := builder.Grant(ctx, user, entitlement.NewAssignmentEntitlement(workspace, client.WorkspaceRoleContributor))
+		require.NoError(t, err)
+		require.Equal(t, client.WorkspaceRoleContributor, fake.members["u1"])
+		require.Equal(t, []string{http.MethodGet, http.MethodPatch}, fake.calls)
+	})
+
+	t.Run("updates a member who joined meanwhile", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{}, joinOnAdd: true}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		_, _, err := builder.Grant(ctx, user, entitlement.NewAssignmentEntitlement(workspace, client.WorkspaceRoleOwner))
+		require.NoError(t, err)
+		require.Equal(t, client.WorkspaceRoleOwner, fake.members["u1"])
+		require.Equal(t, []string{http.MethodGet, http.MethodPost, http.MethodPatch}, fake.calls)
+	})
+}
+
+func TestWorkspaceRevoke(t *testing.T) {
+	ctx := context.Background()
+	user := &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "u1"}}
+
+	t.Run("removes the member then grants again", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{"u1": client.WorkspaceRoleViewer}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+		viewer := entitlement.NewAssignmentEntitlement(workspace, client.WorkspaceRoleViewer)
+
+		annos, err := builder.Revoke(ctx, grant.NewGrant(workspace, client.WorkspaceRoleViewer, user.Id))
+		require.NoError(t, err)
+		require.Empty(t, annos)
+		require.NotContains(t, fake.members, "u1")
+
+		// A cached membership read would report the revoked role as still held.
+		_, annos, err = builder.Grant(ctx, user, viewer)
+		require.NoError(t, err)
+		require.False(t, annos.Contains(&v2.GrantAlreadyExists{}))
+		require.Equal(t, client.WorkspaceRoleViewer, fake.members["u1"])
+	})
+
+	t.Run("keeps a member holding another role", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{"u1": client.WorkspaceRoleAdmin}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		annos, err := builder.Revoke(ctx, grant.NewGrant(workspace, client.WorkspaceRoleViewer, user.Id))
+		require.NoError(t, err)
+		require.True(t, annos.Contains(&v2.GrantAlreadyRevoked{}))
+		require.Equal(t, client.WorkspaceRoleAdmin, fake.members["u1"])
+	})
+
+	t.Run("reports a removed member", func(t *testing.T) {
+		fake := &fakeWorkspace{members: map[string]string{}}
+		builder, workspace := newTestWorkspaceBuilder(t, fake)
+
+		annos, err := builder.Revoke(ctx, grant.NewGrant(workspace, client.WorkspaceRoleViewer, user.Id))
+		require.NoError(t, err)
+		require.True(t, annos.Contains(&v2.GrantAlreadyRevoked{}))
+	})
+}